@@ -0,0 +1,162 @@
+// Handle the connectors API, it's not yet supported by go-kibana-rest
+// API documentation: https://www.elastic.co/guide/en/kibana/master/actions-and-connectors-api.html
+// Supported version:
+//  - v8
+
+package kb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	basePathKibanaActionConnector = "/api/actions/connector" // Base URL to access on Kibana connectors
+)
+
+// kibanaActionConnector is the API connector object
+// Secrets are never returned by Kibana
+type kibanaActionConnector struct {
+	ID               string                 `json:"id,omitempty"`
+	ConnectorTypeID  string                 `json:"connector_type_id,omitempty"`
+	Name             string                 `json:"name"`
+	Config           map[string]interface{} `json:"config,omitempty"`
+	Secrets          map[string]interface{} `json:"secrets,omitempty"`
+	IsPreconfigured  bool                   `json:"is_preconfigured,omitempty"`
+	IsMissingSecrets bool                   `json:"is_missing_secrets,omitempty"`
+}
+
+// String permit to return kibanaActionConnector object as JSON string
+// Secrets are masked
+func (k *kibanaActionConnector) String() string {
+	connector := *k
+	if connector.Secrets != nil {
+		connector.Secrets = map[string]interface{}{"secrets": "***"}
+	}
+	json, _ := json.Marshal(connector)
+	return string(json)
+}
+
+// getKibanaActionConnector permit to get connector with it id
+// It return nil if connector not exist
+func getKibanaActionConnector(ctx context.Context, c *resty.Client, id string, space string) (*kibanaActionConnector, error) {
+
+	if id == "" {
+		return nil, kbapi.NewAPIError(600, "You must provide connector ID")
+	}
+	tflog.Debug(ctx, "Get Kibana action connector", map[string]interface{}{"id": id, "space": space})
+
+	path := kibanaSpacePath(space, fmt.Sprintf("%s/%s", basePathKibanaActionConnector, id))
+	resp, err := c.R().Get(path)
+	if err != nil {
+		return nil, err
+	}
+	tflog.Debug(ctx, "Get Kibana action connector response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		if resp.StatusCode() == 404 {
+			return nil, nil
+		}
+		return nil, kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+	connector := &kibanaActionConnector{}
+	if err = json.Unmarshal(resp.Body(), connector); err != nil {
+		return nil, err
+	}
+
+	return connector, nil
+}
+
+// createKibanaActionConnector permit to create new connector
+// The connector ID is generated by Kibana if not provided
+func createKibanaActionConnector(ctx context.Context, c *resty.Client, connector *kibanaActionConnector, space string) (*kibanaActionConnector, error) {
+
+	if connector == nil {
+		return nil, kbapi.NewAPIError(600, "You must provide connector object")
+	}
+	tflog.Debug(ctx, "Create Kibana action connector", map[string]interface{}{"connector": connector.String(), "space": space})
+
+	// The connector ID is only expected on URL
+	path := basePathKibanaActionConnector
+	if connector.ID != "" {
+		path = fmt.Sprintf("%s/%s", basePathKibanaActionConnector, connector.ID)
+	}
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"connector_type_id": connector.ConnectorTypeID,
+		"name":              connector.Name,
+		"config":            connector.Config,
+		"secrets":           connector.Secrets,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.R().SetBody(jsonData).Post(kibanaSpacePath(space, path))
+	if err != nil {
+		return nil, err
+	}
+	tflog.Debug(ctx, "Create Kibana action connector response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return nil, kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+	connector = &kibanaActionConnector{}
+	if err = json.Unmarshal(resp.Body(), connector); err != nil {
+		return nil, err
+	}
+
+	return connector, nil
+}
+
+// updateKibanaActionConnector permit to update existing connector
+// Secrets are always sent because Kibana replace them on each update
+func updateKibanaActionConnector(ctx context.Context, c *resty.Client, connector *kibanaActionConnector, space string) error {
+
+	if connector == nil {
+		return kbapi.NewAPIError(600, "You must provide connector object")
+	}
+	tflog.Debug(ctx, "Update Kibana action connector", map[string]interface{}{"connector": connector.String(), "space": space})
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"name":    connector.Name,
+		"config":  connector.Config,
+		"secrets": connector.Secrets,
+	})
+	if err != nil {
+		return err
+	}
+	path := kibanaSpacePath(space, fmt.Sprintf("%s/%s", basePathKibanaActionConnector, connector.ID))
+	resp, err := c.R().SetBody(jsonData).Put(path)
+	if err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "Update Kibana action connector response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+
+	return nil
+}
+
+// deleteKibanaActionConnector permit to delete connector
+func deleteKibanaActionConnector(ctx context.Context, c *resty.Client, id string, space string) error {
+
+	if id == "" {
+		return kbapi.NewAPIError(600, "You must provide connector ID")
+	}
+	tflog.Debug(ctx, "Delete Kibana action connector", map[string]interface{}{"id": id, "space": space})
+
+	path := kibanaSpacePath(space, fmt.Sprintf("%s/%s", basePathKibanaActionConnector, id))
+	resp, err := c.R().Delete(path)
+	if err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "Delete Kibana action connector response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+// Handle the alerting rules API, it's not yet supported by go-kibana-rest
+// API documentation: https://www.elastic.co/guide/en/kibana/master/alerting-apis.html
+// Supported version:
+//  - v8
+
+package kb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	basePathKibanaAlertingRule = "/api/alerting/rule" // Base URL to access on Kibana alerting rules
+)
+
+// kibanaAlertingRule is the API alerting rule object
+type kibanaAlertingRule struct {
+	ID         string                     `json:"id,omitempty"`
+	Name       string                     `json:"name"`
+	RuleTypeID string                     `json:"rule_type_id,omitempty"`
+	Consumer   string                     `json:"consumer,omitempty"`
+	Schedule   kibanaAlertingRuleSchedule `json:"schedule"`
+	Params     map[string]interface{}     `json:"params"`
+	Actions    []kibanaAlertingRuleAction `json:"actions"`
+	Tags       []string                   `json:"tags"`
+	NotifyWhen string                     `json:"notify_when,omitempty"`
+	Throttle   string                     `json:"throttle,omitempty"`
+	Enabled    bool                       `json:"enabled"`
+}
+
+// kibanaAlertingRuleSchedule is the API schedule object
+type kibanaAlertingRuleSchedule struct {
+	Interval string `json:"interval"`
+}
+
+// kibanaAlertingRuleAction is the API action object
+type kibanaAlertingRuleAction struct {
+	Group     string                             `json:"group,omitempty"`
+	ID        string                             `json:"id"`
+	Params    map[string]interface{}             `json:"params"`
+	Frequency *kibanaAlertingRuleActionFrequency `json:"frequency,omitempty"`
+}
+
+// kibanaAlertingRuleActionFrequency is the API action frequency object
+type kibanaAlertingRuleActionFrequency struct {
+	Summary    bool    `json:"summary"`
+	NotifyWhen string  `json:"notify_when"`
+	Throttle   *string `json:"throttle"`
+}
+
+// String permit to return kibanaAlertingRule object as JSON string
+func (k *kibanaAlertingRule) String() string {
+	json, _ := json.Marshal(k)
+	return string(json)
+}
+
+// getKibanaAlertingRule permit to get alerting rule with it id
+// It return nil if alerting rule not exist
+func getKibanaAlertingRule(ctx context.Context, c *resty.Client, id string, space string) (*kibanaAlertingRule, error) {
+
+	if id == "" {
+		return nil, kbapi.NewAPIError(600, "You must provide alerting rule ID")
+	}
+	tflog.Debug(ctx, "Get Kibana alerting rule", map[string]interface{}{"id": id, "space": space})
+
+	path := kibanaSpacePath(space, fmt.Sprintf("%s/%s", basePathKibanaAlertingRule, id))
+	resp, err := c.R().Get(path)
+	if err != nil {
+		return nil, err
+	}
+	tflog.Debug(ctx, "Get Kibana alerting rule response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		if resp.StatusCode() == 404 {
+			return nil, nil
+		}
+		return nil, kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+	rule := &kibanaAlertingRule{}
+	if err = json.Unmarshal(resp.Body(), rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// createKibanaAlertingRule permit to create new alerting rule
+// The rule ID is generated by Kibana if not provided
+func createKibanaAlertingRule(ctx context.Context, c *resty.Client, rule *kibanaAlertingRule, space string) (*kibanaAlertingRule, error) {
+
+	if rule == nil {
+		return nil, kbapi.NewAPIError(600, "You must provide alerting rule object")
+	}
+	tflog.Debug(ctx, "Create Kibana alerting rule", map[string]interface{}{"rule": rule.String(), "space": space})
+
+	// The rule ID is only expected on URL
+	path := basePathKibanaAlertingRule
+	if rule.ID != "" {
+		path = fmt.Sprintf("%s/%s", basePathKibanaAlertingRule, rule.ID)
+	}
+	payload := *rule
+	payload.ID = ""
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.R().SetBody(jsonData).Post(kibanaSpacePath(space, path))
+	if err != nil {
+		return nil, err
+	}
+	tflog.Debug(ctx, "Create Kibana alerting rule response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return nil, kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+	rule = &kibanaAlertingRule{}
+	if err = json.Unmarshal(resp.Body(), rule); err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// updateKibanaAlertingRule permit to update existing alerting rule
+// The rule type, the consumer and the enabled state can't be updated with this API
+func updateKibanaAlertingRule(ctx context.Context, c *resty.Client, rule *kibanaAlertingRule, space string) error {
+
+	if rule == nil {
+		return kbapi.NewAPIError(600, "You must provide alerting rule object")
+	}
+	tflog.Debug(ctx, "Update Kibana alerting rule", map[string]interface{}{"rule": rule.String(), "space": space})
+
+	payload := map[string]interface{}{
+		"name":     rule.Name,
+		"schedule": rule.Schedule,
+		"params":   rule.Params,
+		"actions":  rule.Actions,
+		"tags":     rule.Tags,
+	}
+	if rule.NotifyWhen != "" {
+		payload["notify_when"] = rule.NotifyWhen
+	}
+	if rule.Throttle != "" {
+		payload["throttle"] = rule.Throttle
+	}
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	path := kibanaSpacePath(space, fmt.Sprintf("%s/%s", basePathKibanaAlertingRule, rule.ID))
+	resp, err := c.R().SetBody(jsonData).Put(path)
+	if err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "Update Kibana alerting rule response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+
+	return nil
+}
+
+// enableKibanaAlertingRule permit to enable or disable alerting rule
+func enableKibanaAlertingRule(ctx context.Context, c *resty.Client, id string, enabled bool, space string) error {
+
+	if id == "" {
+		return kbapi.NewAPIError(600, "You must provide alerting rule ID")
+	}
+	tflog.Debug(ctx, "Enable Kibana alerting rule", map[string]interface{}{"id": id, "enabled": enabled, "space": space})
+
+	action := "_disable"
+	if enabled {
+		action = "_enable"
+	}
+	path := kibanaSpacePath(space, fmt.Sprintf("%s/%s/%s", basePathKibanaAlertingRule, id, action))
+	resp, err := c.R().Post(path)
+	if err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "Enable Kibana alerting rule response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+
+	return nil
+}
+
+// deleteKibanaAlertingRule permit to delete alerting rule
+func deleteKibanaAlertingRule(ctx context.Context, c *resty.Client, id string, space string) error {
+
+	if id == "" {
+		return kbapi.NewAPIError(600, "You must provide alerting rule ID")
+	}
+	tflog.Debug(ctx, "Delete Kibana alerting rule", map[string]interface{}{"id": id, "space": space})
+
+	path := kibanaSpacePath(space, fmt.Sprintf("%s/%s", basePathKibanaAlertingRule, id))
+	resp, err := c.R().Delete(path)
+	if err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "Delete Kibana alerting rule response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+
+	return nil
+}
@@ -0,0 +1,247 @@
+// Handle the data views API, it's not yet supported by go-kibana-rest
+// API documentation: https://www.elastic.co/guide/en/kibana/master/data-views-api.html
+// Supported version:
+//  - v8
+
+package kb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	basePathKibanaDataView = "/api/data_views/data_view" // Base URL to access on Kibana data views
+)
+
+// kibanaDataView is the API data view object
+type kibanaDataView struct {
+	ID              string                                `json:"id,omitempty"`
+	Title           string                                `json:"title,omitempty"`
+	Name            string                                `json:"name,omitempty"`
+	TimeFieldName   string                                `json:"timeFieldName,omitempty"`
+	SourceFilters   []kibanaDataViewSourceFilter          `json:"sourceFilters,omitempty"`
+	FieldFormats    map[string]kibanaDataViewFieldFormat  `json:"fieldFormats,omitempty"`
+	RuntimeFieldMap map[string]kibanaDataViewRuntimeField `json:"runtimeFieldMap,omitempty"`
+	FieldAttrs      map[string]kibanaDataViewFieldAttr    `json:"fieldAttrs,omitempty"`
+	AllowNoIndex    bool                                  `json:"allowNoIndex,omitempty"`
+	Namespaces      []string                              `json:"namespaces,omitempty"`
+}
+
+// kibanaDataViewSourceFilter is the API source filter object
+type kibanaDataViewSourceFilter struct {
+	Value string `json:"value"`
+}
+
+// kibanaDataViewFieldFormat is the API field format object
+type kibanaDataViewFieldFormat struct {
+	ID     string                 `json:"id"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// kibanaDataViewRuntimeField is the API runtime field object
+type kibanaDataViewRuntimeField struct {
+	Type   string                            `json:"type"`
+	Script *kibanaDataViewRuntimeFieldScript `json:"script,omitempty"`
+}
+
+// kibanaDataViewRuntimeFieldScript is the API runtime field script object
+type kibanaDataViewRuntimeFieldScript struct {
+	Source string `json:"source"`
+}
+
+// kibanaDataViewFieldAttr is the API field attribute object
+type kibanaDataViewFieldAttr struct {
+	CustomLabel string `json:"customLabel,omitempty"`
+	Count       int    `json:"count,omitempty"`
+}
+
+// kibanaDataViewResponse is the API response that wrap data view object
+type kibanaDataViewResponse struct {
+	DataView *kibanaDataView `json:"data_view"`
+}
+
+// String permit to return kibanaDataView object as JSON string
+func (k *kibanaDataView) String() string {
+	json, _ := json.Marshal(k)
+	return string(json)
+}
+
+// getKibanaDataView permit to get data view with it id
+// It return nil if data view not exist
+func getKibanaDataView(ctx context.Context, c *resty.Client, id string, space string) (*kibanaDataView, error) {
+
+	if id == "" {
+		return nil, kbapi.NewAPIError(600, "You must provide data view ID")
+	}
+	tflog.Debug(ctx, "Get Kibana data view", map[string]interface{}{"id": id, "space": space})
+
+	path := kibanaSpacePath(space, fmt.Sprintf("%s/%s", basePathKibanaDataView, id))
+	resp, err := c.R().Get(path)
+	if err != nil {
+		return nil, err
+	}
+	tflog.Debug(ctx, "Get Kibana data view response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		if resp.StatusCode() == 404 {
+			return nil, nil
+		}
+		return nil, kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+	dataViewResponse := &kibanaDataViewResponse{}
+	if err = json.Unmarshal(resp.Body(), dataViewResponse); err != nil {
+		return nil, err
+	}
+
+	return dataViewResponse.DataView, nil
+}
+
+// createKibanaDataView permit to create new data view
+func createKibanaDataView(ctx context.Context, c *resty.Client, dataView *kibanaDataView, space string) (*kibanaDataView, error) {
+
+	if dataView == nil {
+		return nil, kbapi.NewAPIError(600, "You must provide data view object")
+	}
+	tflog.Debug(ctx, "Create Kibana data view", map[string]interface{}{"data_view": dataView.String(), "space": space})
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"data_view": dataView,
+	})
+	if err != nil {
+		return nil, err
+	}
+	path := kibanaSpacePath(space, basePathKibanaDataView)
+	resp, err := c.R().SetBody(jsonData).Post(path)
+	if err != nil {
+		return nil, err
+	}
+	tflog.Debug(ctx, "Create Kibana data view response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return nil, kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+	dataViewResponse := &kibanaDataViewResponse{}
+	if err = json.Unmarshal(resp.Body(), dataViewResponse); err != nil {
+		return nil, err
+	}
+
+	return dataViewResponse.DataView, nil
+}
+
+// updateKibanaDataView permit to update existing data view
+// All updatable attributes are sent to remove the ones not set anymore
+func updateKibanaDataView(ctx context.Context, c *resty.Client, dataView *kibanaDataView, space string) error {
+
+	if dataView == nil {
+		return kbapi.NewAPIError(600, "You must provide data view object")
+	}
+	tflog.Debug(ctx, "Update Kibana data view", map[string]interface{}{"data_view": dataView.String(), "space": space})
+
+	sourceFilters := dataView.SourceFilters
+	if sourceFilters == nil {
+		sourceFilters = []kibanaDataViewSourceFilter{}
+	}
+	fieldFormats := dataView.FieldFormats
+	if fieldFormats == nil {
+		fieldFormats = map[string]kibanaDataViewFieldFormat{}
+	}
+	runtimeFieldMap := dataView.RuntimeFieldMap
+	if runtimeFieldMap == nil {
+		runtimeFieldMap = map[string]kibanaDataViewRuntimeField{}
+	}
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"data_view": map[string]interface{}{
+			"title":           dataView.Title,
+			"name":            dataView.Name,
+			"timeFieldName":   dataView.TimeFieldName,
+			"sourceFilters":   sourceFilters,
+			"fieldFormats":    fieldFormats,
+			"runtimeFieldMap": runtimeFieldMap,
+			"allowNoIndex":    dataView.AllowNoIndex,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	path := kibanaSpacePath(space, fmt.Sprintf("%s/%s", basePathKibanaDataView, dataView.ID))
+	resp, err := c.R().SetBody(jsonData).Post(path)
+	if err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "Update Kibana data view response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+
+	return nil
+}
+
+// updateKibanaDataViewFields permit to update the fields attributes of data view
+// Field set to nil is reset
+func updateKibanaDataViewFields(ctx context.Context, c *resty.Client, id string, fields map[string]*kibanaDataViewFieldAttr, space string) error {
+
+	if id == "" {
+		return kbapi.NewAPIError(600, "You must provide data view ID")
+	}
+	tflog.Debug(ctx, "Update Kibana data view fields", map[string]interface{}{"id": id, "fields": fields, "space": space})
+
+	// Use explicit null to reset custom label and count
+	payload := map[string]interface{}{}
+	for name, field := range fields {
+		item := map[string]interface{}{
+			"customLabel": nil,
+			"count":       nil,
+		}
+		if field != nil && field.CustomLabel != "" {
+			item["customLabel"] = field.CustomLabel
+		}
+		if field != nil && field.Count != 0 {
+			item["count"] = field.Count
+		}
+		payload[name] = item
+	}
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"fields": payload,
+	})
+	if err != nil {
+		return err
+	}
+	path := kibanaSpacePath(space, fmt.Sprintf("%s/%s/fields", basePathKibanaDataView, id))
+	resp, err := c.R().SetBody(jsonData).Post(path)
+	if err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "Update Kibana data view fields response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+
+	return nil
+}
+
+// deleteKibanaDataView permit to delete data view
+func deleteKibanaDataView(ctx context.Context, c *resty.Client, id string, space string) error {
+
+	if id == "" {
+		return kbapi.NewAPIError(600, "You must provide data view ID")
+	}
+	tflog.Debug(ctx, "Delete Kibana data view", map[string]interface{}{"id": id, "space": space})
+
+	path := kibanaSpacePath(space, fmt.Sprintf("%s/%s", basePathKibanaDataView, id))
+	resp, err := c.R().Delete(path)
+	if err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "Delete Kibana data view response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+
+	return nil
+}
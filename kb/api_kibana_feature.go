@@ -0,0 +1,52 @@
+// Handle the features API, it's not yet supported by go-kibana-rest
+// API documentation: https://www.elastic.co/guide/en/kibana/master/features-api-get.html
+// Supported version:
+//  - v8
+
+package kb
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	basePathKibanaFeature = "/api/features" // Base URL to access on Kibana features
+)
+
+// kibanaFeature is the API feature object
+type kibanaFeature struct {
+	ID         string                     `json:"id"`
+	Name       string                     `json:"name"`
+	Category   *kibanaFeatureCategory     `json:"category,omitempty"`
+	Privileges map[string]json.RawMessage `json:"privileges,omitempty"`
+}
+
+// kibanaFeatureCategory is the API feature category object
+type kibanaFeatureCategory struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+// getKibanaFeatures permit to get all Kibana features
+func getKibanaFeatures(ctx context.Context, c *resty.Client) ([]kibanaFeature, error) {
+
+	resp, err := c.R().Get(basePathKibanaFeature)
+	if err != nil {
+		return nil, err
+	}
+	tflog.Debug(ctx, "Get Kibana features response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return nil, kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+	features := make([]kibanaFeature, 0)
+	if err = json.Unmarshal(resp.Body(), &features); err != nil {
+		return nil, err
+	}
+
+	return features, nil
+}
@@ -0,0 +1,120 @@
+// Extend the role management API to handle Elasticsearch privileges not yet supported by go-kibana-rest
+// API documentation: https://www.elastic.co/guide/en/kibana/master/role-management-api.html
+// Supported version:
+//  - v8
+
+package kb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	basePathKibanaRole = "/api/security/role" // Base URL to access on Kibana role management
+)
+
+// kibanaRole is the API role object
+type kibanaRole struct {
+	Name              string                             `json:"name,omitempty"`
+	Metadata          map[string]interface{}             `json:"metadata,omitempty"`
+	TransientMetadata *kbapi.KibanaRoleTransientMetadata `json:"transient_metadata,omitempty"`
+	Elasticsearch     *kibanaRoleElasticsearch           `json:"elasticsearch,omitempty"`
+	Kibana            []kbapi.KibanaRoleKibana           `json:"kibana,omitempty"`
+}
+
+// kibanaRoleElasticsearch is the API Elasticsearch object with remote privileges
+type kibanaRoleElasticsearch struct {
+	Indices       []kibanaRoleElasticsearchIndice        `json:"indices,omitempty"`
+	RemoteIndices []kibanaRoleElasticsearchRemoteIndice  `json:"remote_indices,omitempty"`
+	Cluster       []string                               `json:"cluster,omitempty"`
+	RemoteCluster []kibanaRoleElasticsearchRemoteCluster `json:"remote_cluster,omitempty"`
+	RunAs         []string                               `json:"run_as,omitempty"`
+}
+
+// kibanaRoleElasticsearchIndice is the API indice object
+type kibanaRoleElasticsearchIndice struct {
+	kbapi.KibanaRoleElasticsearchIndice
+	AllowRestrictedIndices bool `json:"allow_restricted_indices,omitempty"`
+}
+
+// kibanaRoleElasticsearchRemoteIndice is the API remote indice object
+type kibanaRoleElasticsearchRemoteIndice struct {
+	kibanaRoleElasticsearchIndice
+	Clusters []string `json:"clusters,omitempty"`
+}
+
+// kibanaRoleElasticsearchRemoteCluster is the API remote cluster object
+type kibanaRoleElasticsearchRemoteCluster struct {
+	Clusters   []string `json:"clusters,omitempty"`
+	Privileges []string `json:"privileges,omitempty"`
+}
+
+// String permit to return kibanaRole object as JSON string
+func (k *kibanaRole) String() string {
+	json, _ := json.Marshal(k)
+	return string(json)
+}
+
+// getKibanaRole permit to get the kibana role with it name
+// It return nil if role not exist
+func getKibanaRole(ctx context.Context, c *resty.Client, name string) (*kibanaRole, error) {
+
+	if name == "" {
+		return nil, kbapi.NewAPIError(600, "You must provide kibana role name")
+	}
+	tflog.Debug(ctx, "Get Kibana role", map[string]interface{}{"name": name})
+
+	path := fmt.Sprintf("%s/%s", basePathKibanaRole, name)
+	resp, err := c.R().Get(path)
+	if err != nil {
+		return nil, err
+	}
+	tflog.Debug(ctx, "Get Kibana role response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		if resp.StatusCode() == 404 {
+			return nil, nil
+		}
+		return nil, kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+	role := &kibanaRole{}
+	if err = json.Unmarshal(resp.Body(), role); err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// createOrUpdateKibanaRole permit to create or update the kibana role
+func createOrUpdateKibanaRole(ctx context.Context, c *resty.Client, role *kibanaRole) error {
+
+	if role == nil {
+		return kbapi.NewAPIError(600, "You must provide kibana role object")
+	}
+	tflog.Debug(ctx, "Create or update Kibana role", map[string]interface{}{"role": role.String()})
+
+	// The role name is only expected on URL
+	path := fmt.Sprintf("%s/%s", basePathKibanaRole, role.Name)
+	payload := *role
+	payload.Name = ""
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.R().SetBody(jsonData).Put(path)
+	if err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "Create or update Kibana role response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+
+	return nil
+}
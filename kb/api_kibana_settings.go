@@ -0,0 +1,86 @@
+// Handle the advanced settings API, it's not yet supported by go-kibana-rest
+// The API is not documented, it's the one used by Kibana UI
+// Supported version:
+//  - v8
+
+package kb
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	basePathKibanaSettings       = "/api/kibana/settings"        // Base URL to access on Kibana advanced settings
+	basePathKibanaGlobalSettings = "/api/kibana/global_settings" // Base URL to access on Kibana global advanced settings
+)
+
+// kibanaSetting is the API setting object
+type kibanaSetting struct {
+	UserValue    interface{} `json:"userValue,omitempty"`
+	IsOverridden bool        `json:"isOverridden,omitempty"`
+}
+
+// kibanaSettingsResponse is the API response that wrap settings
+type kibanaSettingsResponse struct {
+	Settings map[string]kibanaSetting `json:"settings"`
+}
+
+// kibanaSettingsPath permit to get the settings path of space or the global one
+func kibanaSettingsPath(space string, global bool) string {
+	if global {
+		return basePathKibanaGlobalSettings
+	}
+	return kibanaSpacePath(space, basePathKibanaSettings)
+}
+
+// getKibanaSettings permit to get the advanced settings set by user on space or globally
+func getKibanaSettings(ctx context.Context, c *resty.Client, space string, global bool) (map[string]kibanaSetting, error) {
+	tflog.Debug(ctx, "Get Kibana settings", map[string]interface{}{"space": space, "global": global})
+
+	resp, err := c.R().Get(kibanaSettingsPath(space, global))
+	if err != nil {
+		return nil, err
+	}
+	tflog.Debug(ctx, "Get Kibana settings response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return nil, kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+	settingsResponse := &kibanaSettingsResponse{}
+	if err = json.Unmarshal(resp.Body(), settingsResponse); err != nil {
+		return nil, err
+	}
+
+	return settingsResponse.Settings, nil
+}
+
+// updateKibanaSettings permit to change advanced settings on space or globally
+// Setting with nil value is reset to default value
+func updateKibanaSettings(ctx context.Context, c *resty.Client, changes map[string]interface{}, space string, global bool) error {
+
+	if len(changes) == 0 {
+		return kbapi.NewAPIError(600, "You must provide settings to change")
+	}
+	tflog.Debug(ctx, "Update Kibana settings", map[string]interface{}{"changes": changes, "space": space, "global": global})
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"changes": changes,
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := c.R().SetBody(jsonData).Post(kibanaSettingsPath(space, global))
+	if err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "Update Kibana settings response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+
+	return nil
+}
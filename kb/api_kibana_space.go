@@ -0,0 +1,111 @@
+// Extend the spaces API to handle the fields not yet supported by go-kibana-rest
+// API documentation: https://www.elastic.co/guide/en/kibana/master/spaces-api.html
+// Supported version:
+//  - v8
+
+package kb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	basePathKibanaSpace = "/api/spaces/space" // Base URL to access on Kibana spaces
+)
+
+// kibanaSpace is the API space object with image and solution
+type kibanaSpace struct {
+	kbapi.KibanaSpace
+	ImageURL string `json:"imageUrl,omitempty"`
+	Solution string `json:"solution,omitempty"`
+}
+
+// String permit to return kibanaSpace object as JSON string
+func (k *kibanaSpace) String() string {
+	json, _ := json.Marshal(k)
+	return string(json)
+}
+
+// getKibanaSpace permit to get the kibana space with it id
+// It return nil if space not exist
+func getKibanaSpace(ctx context.Context, c *resty.Client, id string) (*kibanaSpace, error) {
+
+	if id == "" {
+		return nil, kbapi.NewAPIError(600, "You must provide kibana space ID")
+	}
+	tflog.Debug(ctx, "Get Kibana space", map[string]interface{}{"id": id})
+
+	path := fmt.Sprintf("%s/%s", basePathKibanaSpace, id)
+	resp, err := c.R().Get(path)
+	if err != nil {
+		return nil, err
+	}
+	tflog.Debug(ctx, "Get Kibana space response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		if resp.StatusCode() == 404 {
+			return nil, nil
+		}
+		return nil, kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+	space := &kibanaSpace{}
+	if err = json.Unmarshal(resp.Body(), space); err != nil {
+		return nil, err
+	}
+
+	return space, nil
+}
+
+// createKibanaSpace permit to create new kibana space
+func createKibanaSpace(ctx context.Context, c *resty.Client, space *kibanaSpace) error {
+
+	if space == nil {
+		return kbapi.NewAPIError(600, "You must provide kibana space object")
+	}
+	tflog.Debug(ctx, "Create Kibana space", map[string]interface{}{"kibana_space": space.String()})
+
+	jsonData, err := json.Marshal(space)
+	if err != nil {
+		return err
+	}
+	resp, err := c.R().SetBody(jsonData).Post(basePathKibanaSpace)
+	if err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "Create Kibana space response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+
+	return nil
+}
+
+// updateKibanaSpace permit to update the kibana space
+func updateKibanaSpace(ctx context.Context, c *resty.Client, space *kibanaSpace) error {
+
+	if space == nil {
+		return kbapi.NewAPIError(600, "You must provide kibana space object")
+	}
+	tflog.Debug(ctx, "Update Kibana space", map[string]interface{}{"kibana_space": space.String()})
+
+	jsonData, err := json.Marshal(space)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("%s/%s", basePathKibanaSpace, space.ID)
+	resp, err := c.R().SetBody(jsonData).Put(path)
+	if err != nil {
+		return err
+	}
+	tflog.Debug(ctx, "Update Kibana space response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+
+	return nil
+}
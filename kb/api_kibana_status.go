@@ -0,0 +1,51 @@
+// Handle the status API with context, so the connexion check can be canceled
+// API documentation: https://www.elastic.co/guide/en/kibana/master/access.html
+// Supported version:
+//  - v8
+
+package kb
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/pkg/errors"
+)
+
+const (
+	basePathKibanaStatus = "/api/status" // Base URL to access on Kibana status
+)
+
+// kibanaStatus is the API status object
+// Only the version is needed by provider
+type kibanaStatus struct {
+	Version struct {
+		Number string `json:"number"`
+	} `json:"version"`
+}
+
+// getKibanaVersion permit to get the Kibana version from status API
+func getKibanaVersion(ctx context.Context, c *resty.Client) (string, error) {
+
+	resp, err := c.R().SetContext(ctx).Get(basePathKibanaStatus)
+	if err != nil {
+		return "", err
+	}
+	tflog.Debug(ctx, "Get Kibana version response", map[string]interface{}{"status": resp.StatusCode(), "body": resp.String()})
+	if resp.StatusCode() >= 300 {
+		return "", kbapi.NewAPIError(resp.StatusCode(), resp.Status())
+	}
+
+	status := &kibanaStatus{}
+	if err = json.Unmarshal(resp.Body(), status); err != nil {
+		return "", err
+	}
+	if status.Version.Number == "" {
+		return "", errors.New("Status is empty, somethink wrong with Kibana ?")
+	}
+
+	return status.Version.Number, nil
+}
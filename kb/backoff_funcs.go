@@ -0,0 +1,58 @@
+package kb
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// backoffOptions is the options used to retry with exponential backoff
+type backoffOptions struct {
+	retry    int           // The number of retry after the first attempt
+	wait     time.Duration // The initial wait time, doubled after each attempt
+	maxDelay time.Duration // The maximum wait time between two attempts
+}
+
+// retryWithBackoff permit to call fn until it succeed or the number of retry is reached
+// It wait with exponential backoff and jitter between each attempt, and stop when the context is done
+func retryWithBackoff(ctx context.Context, opts backoffOptions, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if attempt >= opts.retry {
+			return err
+		}
+
+		timer := time.NewTimer(backoffDelay(attempt, opts.wait, opts.maxDelay))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Wrapf(err, "Stop to retry after %d attempts (%s)", attempt+1, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// backoffDelay compute the wait time before the next attempt
+// The delay is doubled on each attempt and capped to maxDelay, then a jitter is applied to spread the retries
+func backoffDelay(attempt int, wait time.Duration, maxDelay time.Duration) time.Duration {
+	if wait <= 0 {
+		return 0
+	}
+
+	delay := wait
+	for i := 0; i < attempt && (maxDelay <= 0 || delay < maxDelay); i++ {
+		delay *= 2
+	}
+	if maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+
+	// Full jitter on half of the delay
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,119 @@
+// Return the features available on Kibana
+// Supported version:
+//  - v8
+
+package kb
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceKibanaFeatures() *schema.Resource {
+	return &schema.Resource{
+		Description: "`kibana_features` can be used to retrieve the features available on Kibana.",
+		ReadContext: dataSourceKibanaFeaturesRead,
+
+		Schema: map[string]*schema.Schema{
+			"ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The list of feature IDs",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"features": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The list of features",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The feature ID, to use on space disabled features and on role features",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The feature name",
+						},
+						"category": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The feature category ID",
+						},
+						"privileges": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The privileges available for the feature",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceKibanaFeaturesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var err error
+
+	ctx = m.(*providerMeta).logContext(ctx, "kibana_features", d.Id(), "")
+	conf := m.(*providerMeta).clientWithContext(ctx)
+
+	features, err := getKibanaFeatures(ctx, conf.Client)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ids := make([]string, 0, len(features))
+	for _, feature := range features {
+		ids = append(ids, feature.ID)
+	}
+
+	d.SetId(conf.Client.HostURL)
+	if err = d.Set("ids", ids); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("features", flattenKibanaFeatures(features)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func flattenKibanaFeatures(features []kibanaFeature) []interface{} {
+	tfList := make([]interface{}, 0, len(features))
+	for _, feature := range features {
+		category := ""
+		if feature.Category != nil {
+			category = feature.Category.ID
+		}
+
+		// Privileges is a map, so we sort them to have stable output
+		privileges := make([]string, 0, len(feature.Privileges))
+		for privilege, value := range feature.Privileges {
+			if string(value) == "null" {
+				continue
+			}
+			privileges = append(privileges, privilege)
+		}
+		sort.Strings(privileges)
+
+		tfList = append(tfList, map[string]interface{}{
+			"id":         feature.ID,
+			"name":       feature.Name,
+			"category":   category,
+			"privileges": privileges,
+		})
+	}
+
+	return tfList
+}
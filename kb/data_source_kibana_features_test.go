@@ -0,0 +1,65 @@
+package kb
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceKibanaFeatures(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testDataSourceKibanaFeatures,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckTypeSetElemAttr("data.kibana_features.test", "ids.*", "discover"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.kibana_features.test", "features.*", map[string]string{
+						"id":       "discover",
+						"category": "kibana",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestKibanaFeaturesFlatten(t *testing.T) {
+	features := []kibanaFeature{}
+	data := `[
+		{"id": "discover", "name": "Discover", "category": {"id": "kibana", "label": "Analytics"}, "privileges": {"read": {}, "all": {}}},
+		{"id": "monitoring", "name": "Stack Monitoring", "privileges": null}
+	]`
+	if err := json.Unmarshal([]byte(data), &features); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"id":         "discover",
+			"name":       "Discover",
+			"category":   "kibana",
+			"privileges": []string{"all", "read"},
+		},
+		map[string]interface{}{
+			"id":         "monitoring",
+			"name":       "Stack Monitoring",
+			"category":   "",
+			"privileges": []string{},
+		},
+	}
+	tfList := flattenKibanaFeatures(features)
+	if !reflect.DeepEqual(tfList, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, tfList)
+	}
+}
+
+var testDataSourceKibanaFeatures = `
+data "kibana_features" "test" {
+}
+`
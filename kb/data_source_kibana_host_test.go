@@ -1,10 +1,12 @@
 package kb
 
 import (
+	"context"
 	"os"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
 func TestAccDataSourceKibanaHost(t *testing.T) {
@@ -24,6 +26,21 @@ func TestAccDataSourceKibanaHost(t *testing.T) {
 	})
 }
 
+func TestDataSourceKibanaHostRead(t *testing.T) {
+	meta := newTestProviderMeta(t, nil)
+	d := schema.TestResourceDataRaw(t, dataSourceKibanaHost().Schema, map[string]interface{}{})
+
+	if diags := dataSourceKibanaHostRead(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("Unexpected error: %+v", diags)
+	}
+	if d.Id() != meta.config.Address || d.Get("url").(string) != meta.config.Address {
+		t.Errorf("Unexpected URL %s", d.Get("url").(string))
+	}
+	if d.Get("username").(string) != "elastic" || d.Get("password").(string) != "changeme" {
+		t.Errorf("Unexpected credentials %s / %s", d.Get("username").(string), d.Get("password").(string))
+	}
+}
+
 func testCheckDataSourceKibanaHost(name string) resource.TestCheckFunc {
 	var url, username, password resource.TestCheckFunc
 
@@ -0,0 +1,122 @@
+// Return all spaces of Kibana
+// Supported version:
+//  - v8
+
+package kb
+
+import (
+	"context"
+
+	kbapi "github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceKibanaSpaces() *schema.Resource {
+	return &schema.Resource{
+		Description: "`kibana_spaces` can be used to retrieve all spaces of Kibana.",
+		ReadContext: dataSourceKibanaSpacesRead,
+
+		Schema: map[string]*schema.Schema{
+			"ids": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The list of space IDs",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"spaces": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The list of spaces",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The space ID",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The space name",
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The space description",
+						},
+						"disabled_features": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "The list of features disabled on space",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"initials": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The space initials",
+						},
+						"color": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The space color",
+						},
+						"reserved": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "True if space is reserved, like default space",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceKibanaSpacesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var err error
+
+	ctx = m.(*providerMeta).logContext(ctx, "kibana_spaces", d.Id(), "")
+	conf := m.(*providerMeta).clientWithContext(ctx)
+
+	spaces, err := conf.API.KibanaSpaces.List()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ids := make([]string, 0, len(spaces))
+	for _, space := range spaces {
+		ids = append(ids, space.ID)
+	}
+
+	d.SetId(conf.Client.HostURL)
+	if err = d.Set("ids", ids); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("spaces", flattenKibanaSpaces(spaces)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return nil
+}
+
+func flattenKibanaSpaces(spaces kbapi.KibanaSpaces) []interface{} {
+	tfList := make([]interface{}, 0, len(spaces))
+	for _, space := range spaces {
+		tfList = append(tfList, map[string]interface{}{
+			"id":                space.ID,
+			"name":              space.Name,
+			"description":       space.Description,
+			"disabled_features": space.DisabledFeatures,
+			"initials":          space.Initials,
+			"color":             space.Color,
+			"reserved":          space.Reserved,
+		})
+	}
+
+	return tfList
+}
@@ -0,0 +1,33 @@
+package kb
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccDataSourceKibanaSpaces(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testDataSourceKibanaSpaces,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckTypeSetElemAttr("data.kibana_spaces.test", "ids.*", "default"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.kibana_spaces.test", "spaces.*", map[string]string{
+						"id":       "default",
+						"reserved": "true",
+					}),
+				),
+			},
+		},
+	})
+}
+
+var testDataSourceKibanaSpaces = `
+data "kibana_spaces" "test" {
+}
+`
@@ -2,19 +2,25 @@ package kb
 
 import (
 	"encoding/json"
-	"fmt"
+	"io"
 	"strings"
 
 	eshandler "github.com/disaster37/es-handler/v8"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
 )
 
+// jsonDiffLogEntry is the logger used by JSON diff, its logs are discarded like the ones of go-kibana-rest
+var jsonDiffLogEntry = func() *logrus.Entry {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logrus.NewEntry(logger)
+}()
+
 // suppressEquivalentJSON permit to compare state store as JSON string
 func suppressEquivalentJSON(k, old, new string, d *schema.ResourceData) bool {
 
-	var err error
 	oldObj := map[string]any{}
 	newObj := map[string]any{}
 
@@ -25,19 +31,13 @@ func suppressEquivalentJSON(k, old, new string, d *schema.ResourceData) bool {
 		new = "{}"
 	}
 
-	if err = json.Unmarshal([]byte(old), &oldObj); err != nil {
-		fmt.Printf("[ERR] Error when converting current Json: %s\ndata: %s", err.Error(), old)
-		log.Errorf("Error when converting current Json: %s\ndata: %s", err.Error(), old)
-	}
-	if err = json.Unmarshal([]byte(new), &newObj); err != nil {
-		fmt.Printf("[ERR] Error when converting current Json: %s\ndata: %s", err.Error(), new)
-		log.Errorf("Error when converting current Json: %s\ndata: %s", err.Error(), new)
+	// Invalid JSON is compared as raw string
+	if json.Unmarshal([]byte(old), &oldObj) != nil || json.Unmarshal([]byte(new), &newObj) != nil {
+		return old == new
 	}
 
-	diff, err := eshandler.StandardDiff(oldObj, newObj, logEntry, nil)
+	diff, err := eshandler.StandardDiff(oldObj, newObj, jsonDiffLogEntry, nil)
 	if err != nil {
-		fmt.Printf("[ERR] Error when diff JSON: %s", err.Error())
-		log.Errorf("Error when diff Json: %s", err.Error())
 		return false
 	}
 
@@ -46,7 +46,6 @@ func suppressEquivalentJSON(k, old, new string, d *schema.ResourceData) bool {
 
 func suppressEquivalentJSONWithExclude(k, old, new string, d *schema.ResourceData, exclude map[string]any) bool {
 
-	var err error
 	oldObj := map[string]any{}
 	newObj := map[string]any{}
 
@@ -57,19 +56,13 @@ func suppressEquivalentJSONWithExclude(k, old, new string, d *schema.ResourceDat
 		new = "{}"
 	}
 
-	if err = json.Unmarshal([]byte(old), &oldObj); err != nil {
-		fmt.Printf("[ERR] Error when converting current Json: %s\ndata: %s", err.Error(), old)
-		log.Errorf("Error when converting current Json: %s\ndata: %s", err.Error(), old)
-	}
-	if err = json.Unmarshal([]byte(new), &newObj); err != nil {
-		fmt.Printf("[ERR] Error when converting current Json: %s\ndata: %s", err.Error(), new)
-		log.Errorf("Error when converting current Json: %s\ndata: %s", err.Error(), new)
+	// Invalid JSON is compared as raw string
+	if json.Unmarshal([]byte(old), &oldObj) != nil || json.Unmarshal([]byte(new), &newObj) != nil {
+		return old == new
 	}
 
-	diff, err := eshandler.StandardDiff(oldObj, newObj, logEntry, exclude)
+	diff, err := eshandler.StandardDiff(oldObj, newObj, jsonDiffLogEntry, exclude)
 	if err != nil {
-		fmt.Printf("[ERR] Error when diff JSON: %s", err.Error())
-		log.Errorf("Error when diff Json: %s", err.Error())
 		return false
 	}
 
@@ -120,8 +113,6 @@ func suppressEquivalentNDJSON(k, old, new string, d *schema.ResourceData) bool {
 		res := map[string]any{}
 		if oldJSON != "" {
 			if err = json.Unmarshal([]byte(oldJSON), &res); err != nil {
-				fmt.Printf("[ERR] Error when unmarshal old Json: %s\ndata: %s", err.Error(), oldJSON)
-				log.Errorf("Error when unmarshal old Json: %s\ndata: %s", err.Error(), oldJSON)
 				return false
 			}
 		}
@@ -132,10 +123,7 @@ func suppressEquivalentNDJSON(k, old, new string, d *schema.ResourceData) bool {
 		res := map[string]any{}
 		if newJSON != "" {
 			if err = json.Unmarshal([]byte(newJSON), &res); err != nil {
-				fmt.Printf("[ERR] Error when unmarshal nes Json: %s\ndata: %s", err.Error(), newJSON)
-				log.Errorf("Error when unmarshal new Json: %s\ndata: %s", err.Error(), newJSON)
 				return false
-
 			}
 		}
 		newObjSlice[i] = res
@@ -169,3 +157,13 @@ func suppressEquivalentNDJSON(k, old, new string, d *schema.ResourceData) bool {
 
 	return true
 }
+
+// suppressEquivalentImageURL permit to compare the image file set on configuration with the data URL stored on state
+func suppressEquivalentImageURL(k, old, new string, d *schema.ResourceData) bool {
+	newDataURL, err := imageDataURL(new)
+	if err != nil {
+		return false
+	}
+
+	return old == newDataURL
+}
@@ -0,0 +1,29 @@
+package kb
+
+import (
+	"testing"
+)
+
+func TestSuppressEquivalentJSON(t *testing.T) {
+	testCases := []struct {
+		name       string
+		old        string
+		new        string
+		isSuppress bool
+	}{
+		{name: "same JSON with other format", old: `{"a": 1, "b": 2}`, new: `{"b":2,"a":1}`, isSuppress: true},
+		{name: "empty and empty object", old: "", new: "{}", isSuppress: true},
+		{name: "different JSON", old: `{"a": 1}`, new: `{"a": 2}`, isSuppress: false},
+		{name: "same invalid JSON", old: "not json", new: "not json", isSuppress: true},
+		{name: "invalid JSON and empty object", old: "not json", new: "{}", isSuppress: false},
+	}
+
+	for _, testCase := range testCases {
+		if isSuppress := suppressEquivalentJSON("", testCase.old, testCase.new, nil); isSuppress != testCase.isSuppress {
+			t.Errorf("%s: expected %t, got %t", testCase.name, testCase.isSuppress, isSuppress)
+		}
+		if isSuppress := suppressEquivalentJSONWithExclude("", testCase.old, testCase.new, nil, nil); isSuppress != testCase.isSuppress {
+			t.Errorf("%s with exclude: expected %t, got %t", testCase.name, testCase.isSuppress, isSuppress)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package kb
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// sensitiveLogFields is the log field keys with sensitive values, they are masked on logs
+var sensitiveLogFields = []string{
+	"password",
+	"api_key",
+	"bearer_token",
+	"client_key",
+	"secrets",
+	"authorization",
+}
+
+// logContext permit to add the resource fields on all logs and to mask the sensitive values
+// The credentials set on provider are masked too, even if they are logged on message.
+// The id and space are not added when they are empty, like before create.
+// It's called first on each CRUD function, so the helpers log with the resource fields
+func (m *providerMeta) logContext(ctx context.Context, resourceType string, id string, space string) context.Context {
+	ctx = tflog.SetField(ctx, "resource_type", resourceType)
+	if id != "" {
+		ctx = tflog.SetField(ctx, "id", id)
+	}
+	if space != "" {
+		ctx = tflog.SetField(ctx, "space", space)
+	}
+
+	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, sensitiveLogFields...)
+	if len(m.sensitiveValues) > 0 {
+		ctx = tflog.MaskLogStrings(ctx, m.sensitiveValues...)
+	}
+
+	return ctx
+}
+
+// addSensitiveValues permit to mask the values on all logs, like the credentials
+func (m *providerMeta) addSensitiveValues(values ...string) {
+	for _, value := range values {
+		if value != "" {
+			m.sensitiveValues = append(m.sensitiveValues, value)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package kb
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestLogContext(t *testing.T) {
+	var output bytes.Buffer
+	meta := &providerMeta{}
+	meta.addSensitiveValues("secret-password", "")
+
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	ctx = meta.logContext(ctx, "kibana_data_view", "test", "")
+	tflog.Debug(ctx, "Log with secret-password on message", map[string]interface{}{
+		"password": "other",
+	})
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 log entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry["resource_type"] != "kibana_data_view" || entry["id"] != "test" {
+		t.Errorf("Resource fields must be logged, got %+v", entry)
+	}
+	if _, ok := entry["space"]; ok {
+		t.Errorf("Empty space must not be logged, got %+v", entry)
+	}
+	if entry["password"] != "***" {
+		t.Errorf("Password field must be masked, got %+v", entry)
+	}
+	if strings.Contains(entry["@message"].(string), "secret-password") {
+		t.Errorf("Sensitive value must be masked on message, got %+v", entry)
+	}
+}
@@ -2,20 +2,23 @@ package kb
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"net/url"
+	"strings"
 	"time"
 
-	"github.com/coreos/go-semver/semver"
 	kibana "github.com/disaster37/go-kibana-rest/v8"
-	"github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
-	log "github.com/sirupsen/logrus"
 )
 
-var logEntry *logrus.Entry
+const (
+	minimalKibanaVersion = "8.0.0" // The oldest Kibana version supported by the provider
+)
 
 // Provider define kibana provider
 func Provider() *schema.Provider {
@@ -39,6 +42,20 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("KIBANA_PASSWORD", nil),
 				Description: "Password to use to connect to Kibana using basic auth",
 			},
+			"api_key": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("KIBANA_API_KEY", nil),
+				Description: "Base64 encoded API key to use to connect to Kibana",
+			},
+			"bearer_token": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("KIBANA_BEARER_TOKEN", nil),
+				Description: "Bearer token to use to connect to Kibana",
+			},
 			"cacert_files": {
 				Type:        schema.TypeSet,
 				Optional:    true,
@@ -47,12 +64,62 @@ func Provider() *schema.Provider {
 					Type: schema.TypeString,
 				},
 			},
+			"ca_certs": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "A Custom CA certificates as PEM content",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"headers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Custom headers to add on each request",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"client_cert_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"client_cert"},
+				RequiredWith:  []string{"client_key_file"},
+				Description:   "The client certificate path to use for mutual TLS",
+			},
+			"client_key_file": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"client_key"},
+				RequiredWith:  []string{"client_cert_file"},
+				Description:   "The client private key path to use for mutual TLS",
+			},
+			"client_cert": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				RequiredWith: []string{"client_key"},
+				Description:  "The client certificate as PEM content to use for mutual TLS",
+			},
+			"client_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				RequiredWith: []string{"client_cert"},
+				Description:  "The client private key as PEM content to use for mutual TLS",
+			},
 			"insecure": {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Default:     false,
 				Description: "Disable SSL verification of API calls",
 			},
+			"default_space": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KIBANA_SPACE", defaultKibanaSpace),
+				Description: "The space used by resources when they not set space",
+			},
 			"retry": {
 				Type:        schema.TypeInt,
 				Optional:    true,
@@ -62,14 +129,53 @@ func Provider() *schema.Provider {
 			"wait_before_retry": {
 				Type:        schema.TypeInt,
 				Optional:    true,
-				Default:     10,
-				Description: "Wait time in second before retry connexion",
+				Default:     1,
+				Description: "Initial wait time in second before retry connexion, it's doubled after each retry",
+			},
+			"max_wait": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     60,
+				Description: "Maximum time in second to wait Kibana is online",
+			},
+			"lazy_connect": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("KIBANA_LAZY_CONNECT", false),
+				Description: "Check the connexion on the first API call instead of on provider configuration",
+			},
+			"api_retry": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     3,
+				Description: "Number time it retry API call when Kibana answer with retryable status code",
+			},
+			"api_wait_before_retry": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     1,
+				Description: "Initial wait time in second before retry API call, it's doubled after each retry. The Retry-After header is used when Kibana set it",
+			},
+			"api_max_wait_before_retry": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     30,
+				Description: "Maximum wait time in second before retry API call, including the delay asked by Retry-After header",
+			},
+			"api_retry_status_codes": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: "The HTTP status codes that retry idempotent API call. POST call is only retried on 429. Default to 429, 502 and 503",
+				Elem: &schema.Schema{
+					Type:         schema.TypeInt,
+					ValidateFunc: validation.IntBetween(400, 599),
+				},
 			},
 			"debug": {
 				Type:        schema.TypeBool,
 				Optional:    true,
-				Default:     false,
-				Description: "Set logger to debug on Elasticsearch client",
+				DefaultFunc: schema.EnvDefaultFunc("KIBANA_DEBUG", false),
+				Description: "Log the method, URL, status, latency and bodies of all API calls, with credentials and secrets redacted",
 			},
 		},
 
@@ -79,10 +185,16 @@ func Provider() *schema.Provider {
 			"kibana_object":            resourceKibanaObject(),
 			"kibana_logstash_pipeline": resourceKibanaLogstashPipeline(),
 			"kibana_copy_object":       resourceKibanaCopyObject(),
+			"kibana_data_view":         resourceKibanaDataView(),
+			"kibana_alerting_rule":     resourceKibanaAlertingRule(),
+			"kibana_action_connector":  resourceKibanaActionConnector(),
+			"kibana_advanced_settings": resourceKibanaAdvancedSettings(),
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"kibana_host": dataSourceKibanaHost(),
+			"kibana_host":     dataSourceKibanaHost(),
+			"kibana_features": dataSourceKibanaFeatures(),
+			"kibana_spaces":   dataSourceKibanaSpaces(),
 		},
 
 		ConfigureContextFunc: providerConfigure,
@@ -94,10 +206,25 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 	URL := d.Get("url").(string)
 	insecure := d.Get("insecure").(bool)
 	cacertFiles := convertArrayInterfaceToArrayString(d.Get("cacert_files").(*schema.Set).List())
+	caCerts := convertArrayInterfaceToArrayString(d.Get("ca_certs").(*schema.Set).List())
+	headers := d.Get("headers").(map[string]interface{})
 	username := d.Get("username").(string)
 	password := d.Get("password").(string)
+	apiKey := d.Get("api_key").(string)
+	bearerToken := d.Get("bearer_token").(string)
+	clientCertFile := d.Get("client_cert_file").(string)
+	clientKeyFile := d.Get("client_key_file").(string)
+	clientCert := d.Get("client_cert").(string)
+	clientKey := d.Get("client_key").(string)
+	defaultSpace := d.Get("default_space").(string)
 	retry := d.Get("retry").(int)
 	waitBeforeRetry := d.Get("wait_before_retry").(int)
+	maxWait := d.Get("max_wait").(int)
+	lazyConnect := d.Get("lazy_connect").(bool)
+	apiRetry := d.Get("api_retry").(int)
+	apiWaitBeforeRetry := d.Get("api_wait_before_retry").(int)
+	apiMaxWaitBeforeRetry := d.Get("api_max_wait_before_retry").(int)
+	apiRetryStatusCodes := convertArrayInterfaceToArrayInt(d.Get("api_retry_status_codes").(*schema.Set).List())
 	debug := d.Get("debug").(bool)
 
 	// Checks is valid URL
@@ -105,6 +232,11 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 		return nil, diag.FromErr(err)
 	}
 
+	// Checks only one authentication method is used
+	if err := checkAuthenticationMethod(username, password, apiKey, bearerToken); err != nil {
+		return nil, diag.FromErr(err)
+	}
+
 	// Intialise connexion
 	cfg := kibana.Config{
 		Address: URL,
@@ -118,47 +250,124 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 		cfg.DisableVerifySSL = true
 	}
 
-	client, err := kibana.NewClient(cfg)
+	meta, err := newProviderMeta(cfg)
 	if err != nil {
 		return nil, diag.FromErr(err)
 	}
+	client := meta.client
+	meta.defaultSpace = defaultSpace
+
+	// CA certificates as PEM content
+	for _, caCert := range caCerts {
+		if !x509.NewCertPool().AppendCertsFromPEM([]byte(caCert)) {
+			return nil, diag.FromErr(errors.New("ca_certs must contain valid PEM certificates"))
+		}
+		client.Client.SetRootCertificateFromString(caCert)
+	}
+
+	// Custom headers
+	for header, value := range headers {
+		client.Client.SetHeader(header, value.(string))
+	}
+
+	// Client certificate for mutual TLS
+	if clientCertFile != "" || clientCert != "" {
+		certificate, err := loadClientCertificate(clientCertFile, clientKeyFile, clientCert, clientKey)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		client.Client.SetCertificates(certificate)
+	}
+
+	// The token override the basic auth header
+	if apiKey != "" {
+		client.Client.SetAuthScheme("ApiKey").SetAuthToken(apiKey)
+	}
+	if bearerToken != "" {
+		client.Client.SetAuthScheme("Bearer").SetAuthToken(bearerToken)
+	}
 
-	logger := log.New()
+	// The credentials are masked on all logs
+	meta.addSensitiveValues(password, apiKey, bearerToken, clientKey)
+	for _, value := range headers {
+		meta.addSensitiveValues(value.(string))
+	}
+
+	// The transports must be wrapped after all TLS settings
+	// The debug transport is wrapped first, so each retry is logged
+	transport := client.Client.GetClient().Transport
 	if debug {
-		logger.SetLevel(log.DebugLevel)
+		transport = newDebugTransport(transport, meta.sensitiveValues)
+	}
+
+	// Retry API calls on transient errors, like on Kibana rolling restart
+	// The status API is not retried by transport, because the connexion check already retry it
+	if len(apiRetryStatusCodes) == 0 {
+		apiRetryStatusCodes = defaultRetryStatusCodes
 	}
-	logEntry = log.NewEntry(logger)
+	client.Client.SetTransport(newRetryTransport(transport, backoffOptions{
+		retry:    apiRetry,
+		wait:     time.Duration(apiWaitBeforeRetry) * time.Second,
+		maxDelay: time.Duration(apiMaxWaitBeforeRetry) * time.Second,
+	}, apiRetryStatusCodes))
+	ctx = tflog.SetField(ctx, "url", URL)
+	ctx = tflog.MaskLogStrings(ctx, meta.sensitiveValues...)
 
 	// Test connexion and check kibana version
-	nbFailed := 0
-	isOnline := false
-	var kibanaStatus kbapi.KibanaStatus
-	for !isOnline {
-		kibanaStatus, err = client.API.KibanaStatus.Get()
-		if err == nil {
-			isOnline = true
-		} else {
-			if nbFailed == retry {
-				return nil, diag.FromErr(err)
-			}
-			nbFailed++
-			time.Sleep(time.Duration(waitBeforeRetry) * time.Second)
-		}
+	meta.backoff = backoffOptions{
+		retry:    retry,
+		wait:     time.Duration(waitBeforeRetry) * time.Second,
+		maxDelay: time.Duration(maxWait) * time.Second,
+	}
+	meta.maxWait = time.Duration(maxWait) * time.Second
+	if lazyConnect {
+		// The connexion is checked on the first API call, so validate and plan without Kibana don't hang
+		meta.lazyConnect = true
+		client.Client.OnBeforeRequest(meta.connectBeforeRequest)
+		tflog.Info(ctx, "Lazy connect is enabled, the connexion is checked on first API call")
+		return meta, nil
+	}
+	if err = meta.connect(ctx); err != nil {
+		return nil, diag.FromErr(err)
 	}
+	tflog.Info(ctx, "Connected on Kibana", map[string]interface{}{"version": meta.version.String()})
 
-	if kibanaStatus == nil {
-		return nil, diag.FromErr(errors.New("Status is empty, somethink wrong with Kibana ?"))
+	return meta, nil
+}
+
+// loadClientCertificate permit to load the client certificate from files or from PEM contents
+func loadClientCertificate(certFile, keyFile, cert, key string) (tls.Certificate, error) {
+	if certFile != "" {
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return certificate, errors.Wrapf(err, "Error when load client certificate from %s and %s", certFile, keyFile)
+		}
+		return certificate, nil
 	}
 
-	version := kibanaStatus["version"].(map[string]interface{})["number"].(string)
-	log.Debugf("Server: %s", version)
+	certificate, err := tls.X509KeyPair([]byte(cert), []byte(key))
+	if err != nil {
+		return certificate, errors.Wrap(err, "Error when load client certificate from client_cert and client_key")
+	}
+	return certificate, nil
+}
 
-	vCurrent := semver.New(version)
-	vMinimal := semver.New("8.0.0")
+// checkAuthenticationMethod permit to check that only one authentication method is set
+func checkAuthenticationMethod(username, password, apiKey, bearerToken string) error {
+	methods := make([]string, 0, 3)
+	if username != "" || password != "" {
+		methods = append(methods, "basic auth (username / password)")
+	}
+	if apiKey != "" {
+		methods = append(methods, "api_key")
+	}
+	if bearerToken != "" {
+		methods = append(methods, "bearer_token")
+	}
 
-	if vCurrent.LessThan(*vMinimal) {
-		return nil, diag.FromErr(errors.New("Kibana is older than 7.0.0"))
+	if len(methods) > 1 {
+		return errors.Errorf("Only one authentication method can be used, but found: %s. Check the provider configuration and the environment variables KIBANA_USERNAME, KIBANA_PASSWORD, KIBANA_API_KEY and KIBANA_BEARER_TOKEN", strings.Join(methods, ", "))
 	}
 
-	return client, nil
+	return nil
 }
@@ -0,0 +1,126 @@
+package kb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-semver/semver"
+	kibana "github.com/disaster37/go-kibana-rest/v8"
+	"github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/go-resty/resty/v2"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/pkg/errors"
+)
+
+// providerMeta is the typed client returned by provider configuration and shared with resources and data sources
+// It keep the Kibana client, the config used to create it, the Kibana version and the default space.
+// Tests can inject it with a client that target a fake Kibana
+type providerMeta struct {
+	client       *kibana.Client
+	config       kibana.Config
+	version      *semver.Version
+	defaultSpace string
+
+	// The values masked on logs
+	sensitiveValues []string
+
+	// Connexion check
+	backoff     backoffOptions
+	maxWait     time.Duration
+	mutex       sync.Mutex
+	isConnected bool
+	lazyConnect bool
+}
+
+// newProviderMeta permit to init the provider meta with new Kibana client
+// The version is set after the connexion is checked
+func newProviderMeta(cfg kibana.Config) (*providerMeta, error) {
+	client, err := kibana.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &providerMeta{
+		client: client,
+		config: cfg,
+	}, nil
+}
+
+// connect permit to wait Kibana is online and check it version
+// It retry with exponential backoff until maxWait is reached. Nothing is done when the connexion is already checked
+func (m *providerMeta) connect(ctx context.Context) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.isConnected {
+		return nil
+	}
+
+	if m.maxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.maxWait)
+		defer cancel()
+	}
+
+	var version string
+	err := retryWithBackoff(ctx, m.backoff, func() (err error) {
+		if version, err = getKibanaVersion(ctx, m.client.Client); err != nil {
+			tflog.Debug(ctx, "Kibana is not ready", map[string]interface{}{"error": err.Error()})
+		}
+		return err
+	})
+	if err != nil {
+		return errors.Wrapf(err, "Error when connect on Kibana %s", m.config.Address)
+	}
+
+	vCurrent, err := semver.NewVersion(version)
+	if err != nil {
+		return errors.Wrapf(err, "Error when parse Kibana version %s", version)
+	}
+	vMinimal := semver.New(minimalKibanaVersion)
+	if vCurrent.LessThan(*vMinimal) {
+		return errors.Errorf("Kibana %s is older than %s", vCurrent, vMinimal)
+	}
+
+	m.version = vCurrent
+	m.isConnected = true
+
+	return nil
+}
+
+// connectBeforeRequest is the client middleware used by lazy connect
+// It check the connexion on the first API call done by resource or data source
+func (m *providerMeta) connectBeforeRequest(c *resty.Client, r *resty.Request) error {
+	if r.URL == basePathKibanaStatus {
+		return nil
+	}
+
+	return m.connect(r.Context())
+}
+
+// clientWithContext return a Kibana client that bind all API calls on the context
+// So the resource timeouts and the cancellation are enforced on HTTP requests.
+// It share the HTTP client, so the transport, the TLS settings and the connexions are reused
+func (m *providerMeta) clientWithContext(ctx context.Context) *kibana.Client {
+	client := resty.NewWithClient(m.client.Client.GetClient()).
+		SetBaseURL(m.client.Client.BaseURL).
+		SetDebug(m.client.Client.Debug)
+	client.Header = m.client.Client.Header.Clone()
+	client.UserInfo = m.client.Client.UserInfo
+	client.Token = m.client.Client.Token
+	client.AuthScheme = m.client.Client.AuthScheme
+
+	client.OnBeforeRequest(func(c *resty.Client, r *resty.Request) error {
+		r.SetContext(ctx)
+		return nil
+	})
+	if m.lazyConnect {
+		client.OnBeforeRequest(m.connectBeforeRequest)
+	}
+
+	return &kibana.Client{
+		Client: client,
+		API:    kbapi.New(client),
+	}
+}
@@ -0,0 +1,276 @@
+// Manage the connector in Kibana
+// API documentation: https://www.elastic.co/guide/en/kibana/master/actions-and-connectors-api.html
+// Supported version:
+//  - v8
+
+package kb
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	kbapi "github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// Resource specification to handle connector in Kibana
+func resourceKibanaActionConnector() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceKibanaActionConnectorCreate,
+		ReadContext:   resourceKibanaActionConnectorRead,
+		UpdateContext: resourceKibanaActionConnectorUpdate,
+		DeleteContext: resourceKibanaActionConnectorDelete,
+
+		CustomizeDiff: customizeDiffDefaultSpace("space"),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceKibanaActionConnectorImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"connector_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"space": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Computed: true,
+			},
+			"connector_type_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"config": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				DiffSuppressFunc: suppressEquivalentJSON,
+			},
+			"secrets": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				DiffSuppressFunc: suppressEquivalentJSON,
+			},
+			"secrets_version": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+	}
+}
+
+// Create new connector in Kibana
+func resourceKibanaActionConnectorCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	space := d.Get("space").(string)
+
+	connector, err := buildActionConnector(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	connector.ID = d.Get("connector_id").(string)
+	connector.ConnectorTypeID = d.Get("connector_type_id").(string)
+
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_action_connector", d.Id(), space)
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	connector, err = createKibanaActionConnector(ctx, client.Client, connector, space)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(connector.ID)
+
+	tflog.Info(ctx, "Created connector successfully", map[string]interface{}{"id": connector.ID})
+
+	return resourceKibanaActionConnectorRead(ctx, d, meta)
+}
+
+// Read existing connector in Kibana
+// Secrets are never returned by Kibana, so we keep the ones on state
+func resourceKibanaActionConnectorRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+
+	var err error
+	id := d.Id()
+	space := d.Get("space").(string)
+
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_action_connector", d.Id(), space)
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	connector, err := getKibanaActionConnector(ctx, client.Client, id, space)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if connector == nil {
+		tflog.Warn(ctx, "Connector not found - removing from state", map[string]interface{}{"id": id})
+		d.SetId("")
+		return nil
+	}
+
+	tflog.Debug(ctx, "Get connector successfully", map[string]interface{}{"id": id, "connector": connector.String()})
+
+	config, err := flattenActionConnectorConfig(connector.Config, d.Get("config").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set("connector_id", connector.ID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("space", space); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("connector_type_id", connector.ConnectorTypeID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("name", connector.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("config", config); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Kibana lost the secrets, like when encryption key change. We force to send them again
+	if connector.IsMissingSecrets {
+		tflog.Warn(ctx, "Connector has missing secrets", map[string]interface{}{"id": id})
+		if err = d.Set("secrets", ""); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	tflog.Info(ctx, "Read connector successfully", map[string]interface{}{"id": id})
+
+	return nil
+}
+
+// Update existing connector in Kibana
+func resourceKibanaActionConnectorUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	id := d.Id()
+	space := d.Get("space").(string)
+
+	connector, err := buildActionConnector(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	connector.ID = id
+
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_action_connector", d.Id(), space)
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	if err = updateKibanaActionConnector(ctx, client.Client, connector, space); err != nil {
+		return diag.FromErr(err)
+	}
+
+	tflog.Info(ctx, "Updated connector successfully", map[string]interface{}{"id": id})
+
+	return resourceKibanaActionConnectorRead(ctx, d, meta)
+}
+
+// Delete existing connector in Kibana
+func resourceKibanaActionConnectorDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+
+	id := d.Id()
+	space := d.Get("space").(string)
+
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_action_connector", d.Id(), space)
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	if err := deleteKibanaActionConnector(ctx, client.Client, id, space); err != nil {
+		if apiErr, ok := err.(kbapi.APIError); ok && apiErr.Code == 404 {
+			tflog.Warn(ctx, "Connector not found - removing from state", map[string]interface{}{"id": id})
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+
+	tflog.Info(ctx, "Deleted connector successfully", map[string]interface{}{"id": id})
+	return nil
+
+}
+
+// Import existing connector
+// The ID must be formated as <space>:<connector_id>
+func resourceKibanaActionConnectorImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, errors.Errorf("ID %s must be formated as <space>:<connector_id>", d.Id())
+	}
+
+	d.SetId(parts[1])
+	if err := d.Set("space", parts[0]); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// buildActionConnector permit to build connector from resource data
+func buildActionConnector(d *schema.ResourceData) (*kibanaActionConnector, error) {
+	connector := &kibanaActionConnector{
+		Name:    d.Get("name").(string),
+		Config:  map[string]interface{}{},
+		Secrets: map[string]interface{}{},
+	}
+
+	if config := d.Get("config").(string); config != "" {
+		if err := json.Unmarshal([]byte(config), &connector.Config); err != nil {
+			return nil, errors.Wrap(err, "Error when unmarshal config")
+		}
+	}
+
+	if secrets := d.Get("secrets").(string); secrets != "" {
+		if err := json.Unmarshal([]byte(secrets), &connector.Secrets); err != nil {
+			// Not wrap the error to not leak secrets
+			return nil, errors.New("Error when unmarshal secrets, it must be a valid JSON object")
+		}
+	}
+
+	return connector, nil
+}
+
+// flattenActionConnectorConfig permit to convert config as JSON string
+// When config is already on state, it keep only the keys managed by it to not show default values added by Kibana as drift
+func flattenActionConnectorConfig(config map[string]interface{}, stateConfig string) (string, error) {
+	if stateConfig == "" {
+		return convertInterfaceToJsonString(config)
+	}
+
+	managedConfig := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(stateConfig), &managedConfig); err != nil {
+		return "", errors.Wrap(err, "Error when unmarshal config from state")
+	}
+
+	filteredConfig := make(map[string]interface{}, len(managedConfig))
+	for key := range managedConfig {
+		if value, ok := config[key]; ok {
+			filteredConfig[key] = value
+		}
+	}
+
+	return convertInterfaceToJsonString(filteredConfig)
+}
@@ -0,0 +1,153 @@
+package kb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/pkg/errors"
+)
+
+func TestAccKibanaActionConnector(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckKibanaActionConnectorDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testKibanaActionConnector,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckKibanaActionConnectorExists("kibana_action_connector.test"),
+				),
+			},
+			{
+				Config: testKibanaActionConnectorUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckKibanaActionConnectorExists("kibana_action_connector.test"),
+					resource.TestCheckResourceAttr("kibana_action_connector.test", "secrets_version", "2"),
+				),
+			},
+			{
+				ResourceName:            "kibana_action_connector.test",
+				ImportState:             true,
+				ImportStateId:           "default:terraform-test",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"secrets", "secrets_version", "config"},
+			},
+		},
+	})
+}
+
+func TestKibanaActionConnectorFlattenConfig(t *testing.T) {
+	config := map[string]interface{}{
+		"url":     "http://127.0.0.1",
+		"method":  "post",
+		"hasAuth": true,
+	}
+
+	// Without state, all config is kept
+	result, err := flattenActionConnectorConfig(config, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != `{"hasAuth":true,"method":"post","url":"http://127.0.0.1"}` {
+		t.Errorf("Unexpected config %s", result)
+	}
+
+	// With state, only managed keys are kept
+	result, err = flattenActionConnectorConfig(config, `{"url": "http://localhost", "headers": {}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != `{"url":"http://127.0.0.1"}` {
+		t.Errorf("Unexpected config %s", result)
+	}
+}
+
+func testCheckKibanaActionConnectorExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No connector ID is set")
+		}
+
+		meta := testAccProvider.Meta()
+
+		client := meta.(*providerMeta).client
+		connector, err := getKibanaActionConnector(context.Background(), client.Client, rs.Primary.ID, rs.Primary.Attributes["space"])
+		if err != nil {
+			return err
+		}
+		if connector == nil {
+			return errors.Errorf("Connector %s not found", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
+func testCheckKibanaActionConnectorDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "kibana_action_connector" {
+			continue
+		}
+
+		meta := testAccProvider.Meta()
+
+		client := meta.(*providerMeta).client
+		connector, err := getKibanaActionConnector(context.Background(), client.Client, rs.Primary.ID, rs.Primary.Attributes["space"])
+		if err != nil {
+			return err
+		}
+		if connector == nil {
+			return nil
+		}
+
+		return fmt.Errorf("Connector %q still exists", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+var testKibanaActionConnector = `
+resource "kibana_action_connector" "test" {
+  connector_id      = "terraform-test"
+  name              = "terraform-test"
+  connector_type_id = ".webhook"
+  config            = jsonencode({
+    url     = "http://127.0.0.1:8080"
+    method  = "post"
+    hasAuth = true
+  })
+  secrets           = jsonencode({
+    user     = "terraform"
+    password = "changeme"
+  })
+}
+`
+
+var testKibanaActionConnectorUpdate = `
+resource "kibana_action_connector" "test" {
+  connector_id      = "terraform-test"
+  name              = "terraform-test"
+  connector_type_id = ".webhook"
+  secrets_version   = "2"
+  config            = jsonencode({
+    url     = "http://127.0.0.1:8081"
+    method  = "post"
+    hasAuth = true
+  })
+  secrets           = jsonencode({
+    user     = "terraform"
+    password = "changeme"
+  })
+}
+`
@@ -0,0 +1,295 @@
+// Manage the advanced settings in Kibana
+// The API is not documented, it's the one used by Kibana UI
+// Supported version:
+//  - v8
+
+package kb
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	kbapi "github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	kibanaAdvancedSettingsGlobalID = "global" // ID used for the global scope
+)
+
+// internalSettings is the list of settings stored by Kibana itself, they are never managed
+var internalSettings = []string{"buildNum"}
+
+// Resource specification to handle advanced settings in Kibana
+// Only the settings set on configuration are managed
+func resourceKibanaAdvancedSettings() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceKibanaAdvancedSettingsCreate,
+		ReadContext:   resourceKibanaAdvancedSettingsRead,
+		UpdateContext: resourceKibanaAdvancedSettingsUpdate,
+		DeleteContext: resourceKibanaAdvancedSettingsDelete,
+
+		CustomizeDiff: customizeDiffDefaultSpace("space"),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceKibanaAdvancedSettingsImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"space": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Computed: true,
+			},
+			"global": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				ForceNew: true,
+				Default:  false,
+			},
+			"settings": {
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: suppressEquivalentJSON,
+			},
+		},
+	}
+}
+
+// Create new advanced settings in Kibana
+func resourceKibanaAdvancedSettingsCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	space := d.Get("space").(string)
+	global := d.Get("global").(bool)
+
+	settings, err := buildAdvancedSettings(d.Get("settings").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_advanced_settings", d.Id(), space)
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	if len(settings) > 0 {
+		if err = updateKibanaSettings(ctx, client.Client, settings, space, global); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	id := space
+	if global {
+		id = kibanaAdvancedSettingsGlobalID
+	}
+	d.SetId(id)
+
+	tflog.Info(ctx, "Created advanced settings successfully", map[string]interface{}{"id": id})
+
+	return resourceKibanaAdvancedSettingsRead(ctx, d, meta)
+}
+
+// Read existing advanced settings in Kibana
+func resourceKibanaAdvancedSettingsRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+
+	var err error
+	id := d.Id()
+	space := d.Get("space").(string)
+	global := d.Get("global").(bool)
+
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_advanced_settings", d.Id(), space)
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	remoteSettings, err := getKibanaSettings(ctx, client.Client, space, global)
+	if err != nil {
+		// The space not exist anymore
+		if apiErr, ok := err.(kbapi.APIError); ok && apiErr.Code == 404 {
+			tflog.Warn(ctx, "Advanced settings not found - removing from state", map[string]interface{}{"id": id})
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	tflog.Debug(ctx, "Get advanced settings successfully", map[string]interface{}{"id": id, "remote_settings": remoteSettings})
+
+	// Settings is empty only on import
+	var managedSettings map[string]interface{}
+	if d.Get("settings").(string) != "" {
+		managedSettings, err = buildAdvancedSettings(d.Get("settings").(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	settings, err := flattenAdvancedSettings(remoteSettings, managedSettings)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set("space", space); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("global", global); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("settings", settings); err != nil {
+		return diag.FromErr(err)
+	}
+
+	tflog.Info(ctx, "Read advanced settings successfully", map[string]interface{}{"id": id})
+
+	return nil
+}
+
+// Update existing advanced settings in Kibana
+// The settings removed from configuration are reset to their default values
+func resourceKibanaAdvancedSettingsUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	id := d.Id()
+	space := d.Get("space").(string)
+	global := d.Get("global").(bool)
+
+	oldRaw, newRaw := d.GetChange("settings")
+	oldSettings, err := buildAdvancedSettings(oldRaw.(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	newSettings, err := buildAdvancedSettings(newRaw.(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	changes := make(map[string]interface{}, len(oldSettings)+len(newSettings))
+	for key := range oldSettings {
+		changes[key] = nil
+	}
+	for key, value := range newSettings {
+		changes[key] = value
+	}
+
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_advanced_settings", d.Id(), space)
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	if len(changes) > 0 {
+		if err = updateKibanaSettings(ctx, client.Client, changes, space, global); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	tflog.Info(ctx, "Updated advanced settings successfully", map[string]interface{}{"id": id})
+
+	return resourceKibanaAdvancedSettingsRead(ctx, d, meta)
+}
+
+// Delete existing advanced settings in Kibana
+// It reset the managed settings to their default values
+func resourceKibanaAdvancedSettingsDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+
+	id := d.Id()
+	space := d.Get("space").(string)
+	global := d.Get("global").(bool)
+
+	settings, err := buildAdvancedSettings(d.Get("settings").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	changes := make(map[string]interface{}, len(settings))
+	for key := range settings {
+		changes[key] = nil
+	}
+
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_advanced_settings", d.Id(), space)
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	if len(changes) > 0 {
+		if err = updateKibanaSettings(ctx, client.Client, changes, space, global); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId("")
+
+	tflog.Info(ctx, "Deleted advanced settings successfully", map[string]interface{}{"id": id})
+	return nil
+
+}
+
+// Import existing advanced settings
+// The ID is the space or global. All settings set by user are imported
+func resourceKibanaAdvancedSettingsImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if d.Id() == kibanaAdvancedSettingsGlobalID {
+		if err := d.Set("global", true); err != nil {
+			return nil, err
+		}
+		if err := d.Set("space", providerDefaultSpace(meta)); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := d.Set("space", d.Id()); err != nil {
+			return nil, err
+		}
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// buildAdvancedSettings permit to convert the settings JSON string as map
+func buildAdvancedSettings(raw string) (map[string]interface{}, error) {
+	settings := map[string]interface{}{}
+	if raw == "" {
+		return settings, nil
+	}
+
+	if err := json.Unmarshal([]byte(raw), &settings); err != nil {
+		return nil, errors.Wrap(err, "Error when unmarshal settings")
+	}
+
+	return settings, nil
+}
+
+// flattenAdvancedSettings permit to convert settings as JSON string
+// When managed settings is nil, like on import, it keep all settings set by user. Else it only keep the managed settings
+func flattenAdvancedSettings(remoteSettings map[string]kibanaSetting, managedSettings map[string]interface{}) (string, error) {
+	settings := map[string]interface{}{}
+	if managedSettings == nil {
+		for key, setting := range remoteSettings {
+			if setting.UserValue != nil && !isInternalSetting(key) {
+				settings[key] = setting.UserValue
+			}
+		}
+	} else {
+		for key := range managedSettings {
+			if setting, ok := remoteSettings[key]; ok && setting.UserValue != nil {
+				settings[key] = setting.UserValue
+			}
+		}
+	}
+
+	b, err := json.Marshal(settings)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// isInternalSetting return true if the setting is stored by Kibana itself
+func isInternalSetting(key string) bool {
+	for _, internalSetting := range internalSettings {
+		if key == internalSetting {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,133 @@
+package kb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/pkg/errors"
+)
+
+func TestAccKibanaAdvancedSettings(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckKibanaAdvancedSettingsDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testKibanaAdvancedSettings,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckKibanaAdvancedSettingsExists("kibana_advanced_settings.test"),
+				),
+			},
+			{
+				Config: testKibanaAdvancedSettingsUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckKibanaAdvancedSettingsExists("kibana_advanced_settings.test"),
+				),
+			},
+		},
+	})
+}
+
+func TestKibanaAdvancedSettingsFlatten(t *testing.T) {
+	remoteSettings := map[string]kibanaSetting{
+		"buildNum":         {UserValue: float64(1234)},
+		"dateFormat:tz":    {UserValue: "Europe/Paris"},
+		"theme:darkMode":   {UserValue: true},
+		"timepicker:quick": {},
+	}
+
+	// On import, all settings set by user are kept
+	settings, err := flattenAdvancedSettings(remoteSettings, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings != `{"dateFormat:tz":"Europe/Paris","theme:darkMode":true}` {
+		t.Errorf("Unexpected settings %s", settings)
+	}
+
+	// Else only managed settings are kept
+	settings, err = flattenAdvancedSettings(remoteSettings, map[string]interface{}{
+		"theme:darkMode": false,
+		"defaultIndex":   "logstash",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings != `{"theme:darkMode":true}` {
+		t.Errorf("Unexpected settings %s", settings)
+	}
+}
+
+func testCheckKibanaAdvancedSettingsExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No advanced settings ID is set")
+		}
+
+		meta := testAccProvider.Meta()
+
+		client := meta.(*providerMeta).client
+		settings, err := getKibanaSettings(context.Background(), client.Client, rs.Primary.Attributes["space"], rs.Primary.Attributes["global"] == "true")
+		if err != nil {
+			return err
+		}
+		if settings["dateFormat:tz"].UserValue != "Europe/Paris" {
+			return errors.Errorf("Advanced settings %s not found", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
+func testCheckKibanaAdvancedSettingsDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "kibana_advanced_settings" {
+			continue
+		}
+
+		meta := testAccProvider.Meta()
+
+		client := meta.(*providerMeta).client
+		settings, err := getKibanaSettings(context.Background(), client.Client, rs.Primary.Attributes["space"], rs.Primary.Attributes["global"] == "true")
+		if err != nil {
+			return err
+		}
+		if settings["dateFormat:tz"].UserValue == nil {
+			return nil
+		}
+
+		return fmt.Errorf("Advanced settings %q still exists", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+var testKibanaAdvancedSettings = `
+resource "kibana_advanced_settings" "test" {
+  space    = "default"
+  settings = jsonencode({
+    "dateFormat:tz"  = "Europe/Paris"
+    "theme:darkMode" = true
+  })
+}
+`
+
+var testKibanaAdvancedSettingsUpdate = `
+resource "kibana_advanced_settings" "test" {
+  space    = "default"
+  settings = jsonencode({
+    "dateFormat:tz" = "Europe/Paris"
+  })
+}
+`
@@ -0,0 +1,493 @@
+// Manage the alerting rule in Kibana
+// API documentation: https://www.elastic.co/guide/en/kibana/master/alerting-apis.html
+// Supported version:
+//  - v8
+
+package kb
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	kbapi "github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/pkg/errors"
+)
+
+// notifyWhenValues is the list of supported values for notify_when
+var notifyWhenValues = []string{"onActionGroupChange", "onActiveAlert", "onThrottleInterval"}
+
+// Resource specification to handle alerting rule in Kibana
+func resourceKibanaAlertingRule() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceKibanaAlertingRuleCreate,
+		ReadContext:   resourceKibanaAlertingRuleRead,
+		UpdateContext: resourceKibanaAlertingRuleUpdate,
+		DeleteContext: resourceKibanaAlertingRuleDelete,
+
+		CustomizeDiff: customdiff.All(
+			customizeDiffDefaultSpace("space"),
+			customizeDiffMinimalVersion(
+				versionRequirement{
+					attribute: "actions.frequency",
+					version:   "8.6.0",
+					isUsed:    isAlertingRuleActionFrequencySet,
+				},
+			),
+		),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceKibanaAlertingRuleImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"rule_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"space": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Computed: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"rule_type_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"consumer": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"interval": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"params": {
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: suppressEquivalentJSON,
+			},
+			"actions": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"group": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "default",
+						},
+						"id": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"params": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							DiffSuppressFunc: suppressEquivalentJSON,
+						},
+						"frequency": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Computed: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"summary": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  false,
+									},
+									"notify_when": {
+										Type:         schema.TypeString,
+										Required:     true,
+										ValidateFunc: validation.StringInSlice(notifyWhenValues, false),
+									},
+									"throttle": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"tags": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"notify_when": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice(notifyWhenValues, false),
+			},
+			"throttle": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"enabled": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+		},
+	}
+}
+
+// Create new alerting rule in Kibana
+func resourceKibanaAlertingRuleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	space := d.Get("space").(string)
+
+	rule, err := buildAlertingRule(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	rule.ID = d.Get("rule_id").(string)
+	rule.RuleTypeID = d.Get("rule_type_id").(string)
+	rule.Consumer = d.Get("consumer").(string)
+	rule.Enabled = d.Get("enabled").(bool)
+
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_alerting_rule", d.Id(), space)
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	rule, err = createKibanaAlertingRule(ctx, client.Client, rule, space)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(rule.ID)
+
+	tflog.Info(ctx, "Created alerting rule successfully", map[string]interface{}{"id": rule.ID})
+
+	return resourceKibanaAlertingRuleRead(ctx, d, meta)
+}
+
+// Read existing alerting rule in Kibana
+func resourceKibanaAlertingRuleRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+
+	var err error
+	id := d.Id()
+	space := d.Get("space").(string)
+
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_alerting_rule", d.Id(), space)
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	rule, err := getKibanaAlertingRule(ctx, client.Client, id, space)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if rule == nil {
+		tflog.Warn(ctx, "Alerting rule not found - removing from state", map[string]interface{}{"id": id})
+		d.SetId("")
+		return nil
+	}
+
+	tflog.Debug(ctx, "Get alerting rule successfully", map[string]interface{}{"id": id, "rule": rule.String()})
+
+	params, err := convertInterfaceToJsonString(rule.Params)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	actions, err := flattenAlertingRuleActions(rule.Actions)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = d.Set("rule_id", rule.ID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("space", space); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("name", rule.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("rule_type_id", rule.RuleTypeID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("consumer", rule.Consumer); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("interval", rule.Schedule.Interval); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("params", params); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("actions", actions); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("tags", rule.Tags); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("notify_when", rule.NotifyWhen); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("throttle", rule.Throttle); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("enabled", rule.Enabled); err != nil {
+		return diag.FromErr(err)
+	}
+
+	tflog.Info(ctx, "Read alerting rule successfully", map[string]interface{}{"id": id})
+
+	return nil
+}
+
+// Update existing alerting rule in Kibana
+func resourceKibanaAlertingRuleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	id := d.Id()
+	space := d.Get("space").(string)
+
+	rule, err := buildAlertingRule(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	rule.ID = id
+
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_alerting_rule", d.Id(), space)
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	if d.HasChangesExcept("enabled") {
+		if err = updateKibanaAlertingRule(ctx, client.Client, rule, space); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	// The enabled state is not handled by update API
+	if d.HasChange("enabled") {
+		if err = enableKibanaAlertingRule(ctx, client.Client, id, d.Get("enabled").(bool), space); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	tflog.Info(ctx, "Updated alerting rule successfully", map[string]interface{}{"id": id})
+
+	return resourceKibanaAlertingRuleRead(ctx, d, meta)
+}
+
+// Delete existing alerting rule in Kibana
+func resourceKibanaAlertingRuleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+
+	id := d.Id()
+	space := d.Get("space").(string)
+
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_alerting_rule", d.Id(), space)
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	if err := deleteKibanaAlertingRule(ctx, client.Client, id, space); err != nil {
+		if apiErr, ok := err.(kbapi.APIError); ok && apiErr.Code == 404 {
+			tflog.Warn(ctx, "Alerting rule not found - removing from state", map[string]interface{}{"id": id})
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+
+	tflog.Info(ctx, "Deleted alerting rule successfully", map[string]interface{}{"id": id})
+	return nil
+
+}
+
+// Import existing alerting rule
+// The ID must be formated as <space>:<rule_id>
+func resourceKibanaAlertingRuleImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, errors.Errorf("ID %s must be formated as <space>:<rule_id>", d.Id())
+	}
+
+	d.SetId(parts[1])
+	if err := d.Set("space", parts[0]); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// buildAlertingRule permit to build alerting rule from resource data
+// It only set the updatable fields
+func buildAlertingRule(d *schema.ResourceData) (*kibanaAlertingRule, error) {
+	rule := &kibanaAlertingRule{
+		Name: d.Get("name").(string),
+		Schedule: kibanaAlertingRuleSchedule{
+			Interval: d.Get("interval").(string),
+		},
+		Tags:     convertArrayInterfaceToArrayString(d.Get("tags").(*schema.Set).List()),
+		Throttle: d.Get("throttle").(string),
+	}
+
+	// Kibana reject rule level notify_when with action frequency, and the old values are kept on state because they are computed
+	frequenciesOnConfig := getAlertingRuleActionFrequenciesOnConfig(d)
+	if !hasAlertingRuleActionFrequencyOnConfig(frequenciesOnConfig) {
+		rule.NotifyWhen = d.Get("notify_when").(string)
+	}
+
+	params := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(d.Get("params").(string)), &params); err != nil {
+		return nil, errors.Wrap(err, "Error when unmarshal params")
+	}
+	rule.Params = params
+
+	actions, err := buildAlertingRuleActions(d.Get("actions").([]interface{}), frequenciesOnConfig)
+	if err != nil {
+		return nil, err
+	}
+	rule.Actions = actions
+
+	return rule, nil
+}
+
+// buildAlertingRuleActions permit to build the list of actions
+// The frequency is only set on actions where it's on configuration
+func buildAlertingRuleActions(raws []interface{}, frequenciesOnConfig []bool) ([]kibanaAlertingRuleAction, error) {
+	actions := make([]kibanaAlertingRuleAction, 0, len(raws))
+	for i, raw := range raws {
+		m := raw.(map[string]interface{})
+		action := kibanaAlertingRuleAction{
+			Group:  m["group"].(string),
+			ID:     m["id"].(string),
+			Params: map[string]interface{}{},
+		}
+		if params := m["params"].(string); params != "" {
+			if err := json.Unmarshal([]byte(params), &action.Params); err != nil {
+				return nil, errors.Wrapf(err, "Error when unmarshal params of action %d", i)
+			}
+		}
+		if frequencies := m["frequency"].([]interface{}); i < len(frequenciesOnConfig) && frequenciesOnConfig[i] && len(frequencies) > 0 && frequencies[0] != nil {
+			frequency := frequencies[0].(map[string]interface{})
+			action.Frequency = &kibanaAlertingRuleActionFrequency{
+				Summary:    frequency["summary"].(bool),
+				NotifyWhen: frequency["notify_when"].(string),
+			}
+			if throttle := frequency["throttle"].(string); throttle != "" {
+				action.Frequency.Throttle = &throttle
+			}
+		}
+		actions = append(actions, action)
+	}
+
+	return actions, nil
+}
+
+func flattenAlertingRuleActions(actions []kibanaAlertingRuleAction) ([]interface{}, error) {
+	tfList := make([]interface{}, 0, len(actions))
+	for _, action := range actions {
+		params, err := convertInterfaceToJsonString(action.Params)
+		if err != nil {
+			return nil, err
+		}
+		tfMap := map[string]interface{}{
+			"group":  action.Group,
+			"id":     action.ID,
+			"params": params,
+		}
+		if action.Frequency != nil {
+			frequency := map[string]interface{}{
+				"summary":     action.Frequency.Summary,
+				"notify_when": action.Frequency.NotifyWhen,
+				"throttle":    "",
+			}
+			if action.Frequency.Throttle != nil {
+				frequency["throttle"] = *action.Frequency.Throttle
+			}
+			tfMap["frequency"] = []interface{}{frequency}
+		}
+		tfList = append(tfList, tfMap)
+	}
+
+	return tfList, nil
+}
+
+// isAlertingRuleActionFrequencySet return true if one action use frequency
+func isAlertingRuleActionFrequencySet(d *schema.ResourceDiff) bool {
+	return hasAlertingRuleActionFrequency(d.Get("actions").([]interface{}))
+}
+
+// getAlertingRuleActionFrequenciesOnConfig return for each action if frequency is set on configuration
+// The frequency is computed, so it can be on state even if it's not on configuration.
+// When the raw configuration is not available, it fallback on the current value
+func getAlertingRuleActionFrequenciesOnConfig(d *schema.ResourceData) []bool {
+	raws := d.Get("actions").([]interface{})
+	frequenciesOnConfig := make([]bool, len(raws))
+
+	rawConfig := d.GetRawConfig()
+	if rawConfig.IsNull() || !rawConfig.IsKnown() {
+		for i, raw := range raws {
+			frequenciesOnConfig[i] = hasAlertingRuleActionFrequency([]interface{}{raw})
+		}
+		return frequenciesOnConfig
+	}
+
+	rawActions := rawConfig.GetAttr("actions")
+	if rawActions.IsNull() || !rawActions.IsKnown() {
+		return frequenciesOnConfig
+	}
+	for i, it := 0, rawActions.ElementIterator(); it.Next() && i < len(frequenciesOnConfig); i++ {
+		_, rawAction := it.Element()
+		if rawAction.IsNull() || !rawAction.IsKnown() {
+			continue
+		}
+		if rawFrequency := rawAction.GetAttr("frequency"); !rawFrequency.IsNull() && (!rawFrequency.IsKnown() || rawFrequency.LengthInt() > 0) {
+			frequenciesOnConfig[i] = true
+		}
+	}
+
+	return frequenciesOnConfig
+}
+
+// hasAlertingRuleActionFrequencyOnConfig return true if one action set frequency on configuration
+func hasAlertingRuleActionFrequencyOnConfig(frequenciesOnConfig []bool) bool {
+	for _, frequencyOnConfig := range frequenciesOnConfig {
+		if frequencyOnConfig {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasAlertingRuleActionFrequency return true if one action use frequency
+func hasAlertingRuleActionFrequency(raws []interface{}) bool {
+	for _, raw := range raws {
+		if raw == nil {
+			continue
+		}
+		if frequencies, ok := raw.(map[string]interface{})["frequency"].([]interface{}); ok && len(frequencies) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,293 @@
+package kb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/pkg/errors"
+)
+
+func TestAccKibanaAlertingRule(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckKibanaAlertingRuleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testKibanaAlertingRule,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckKibanaAlertingRuleExists("kibana_alerting_rule.test"),
+					resource.TestCheckResourceAttr("kibana_alerting_rule.test", "enabled", "true"),
+				),
+			},
+			{
+				Config: testKibanaAlertingRuleUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckKibanaAlertingRuleExists("kibana_alerting_rule.test"),
+					resource.TestCheckResourceAttr("kibana_alerting_rule.test", "interval", "5m"),
+					resource.TestCheckResourceAttr("kibana_alerting_rule.test", "tags.#", "2"),
+					resource.TestCheckResourceAttr("kibana_alerting_rule.test", "enabled", "false"),
+				),
+			},
+			{
+				ResourceName:      "kibana_alerting_rule.test",
+				ImportState:       true,
+				ImportStateId:     "default:terraform-test",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestKibanaAlertingRuleActions(t *testing.T) {
+	throttle := "10m"
+	raws := []interface{}{
+		map[string]interface{}{
+			"group":  "threshold met",
+			"id":     "my-connector",
+			"params": `{"message": "{{context.message}}"}`,
+			"frequency": []interface{}{
+				map[string]interface{}{
+					"summary":     false,
+					"notify_when": "onThrottleInterval",
+					"throttle":    throttle,
+				},
+			},
+		},
+	}
+
+	actions, err := buildAlertingRuleActions(raws, []bool{true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []kibanaAlertingRuleAction{
+		{
+			Group: "threshold met",
+			ID:    "my-connector",
+			Params: map[string]interface{}{
+				"message": "{{context.message}}",
+			},
+			Frequency: &kibanaAlertingRuleActionFrequency{
+				Summary:    false,
+				NotifyWhen: "onThrottleInterval",
+				Throttle:   &throttle,
+			},
+		},
+	}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, actions)
+	}
+
+	tfList, err := flattenAlertingRuleActions(actions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tfMap := tfList[0].(map[string]interface{})
+	if tfMap["params"] != `{"message":"{{context.message}}"}` {
+		t.Errorf("Unexpected params %s", tfMap["params"])
+	}
+	if !reflect.DeepEqual(tfMap["frequency"], raws[0].(map[string]interface{})["frequency"]) {
+		t.Errorf("Expected frequency %+v, got %+v", raws[0].(map[string]interface{})["frequency"], tfMap["frequency"])
+	}
+
+	// Action without params must send empty object
+	actions, err = buildAlertingRuleActions([]interface{}{
+		map[string]interface{}{
+			"group":     "default",
+			"id":        "my-connector",
+			"params":    "",
+			"frequency": []interface{}{},
+		},
+	}, []bool{false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actions[0].Params == nil || len(actions[0].Params) != 0 || actions[0].Frequency != nil {
+		t.Errorf("Unexpected action %+v", actions[0])
+	}
+}
+
+func TestKibanaAlertingRuleNotifyWhen(t *testing.T) {
+	config := map[string]interface{}{
+		"name":        "test",
+		"interval":    "1m",
+		"params":      `{"threshold": 1}`,
+		"notify_when": "onActiveAlert",
+		"actions": []interface{}{
+			map[string]interface{}{
+				"id": "my-connector",
+			},
+		},
+	}
+
+	// Rule level notify_when is sent without action frequency
+	rule, err := buildAlertingRule(schema.TestResourceDataRaw(t, resourceKibanaAlertingRule().Schema, config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.NotifyWhen != "onActiveAlert" {
+		t.Errorf("Expected rule level notify_when, got %q", rule.NotifyWhen)
+	}
+
+	// Rule level notify_when is not sent with action frequency
+	config["actions"] = []interface{}{
+		map[string]interface{}{
+			"id": "my-connector",
+			"frequency": []interface{}{
+				map[string]interface{}{"notify_when": "onThrottleInterval", "throttle": "10m"},
+			},
+		},
+	}
+	rule, err = buildAlertingRule(schema.TestResourceDataRaw(t, resourceKibanaAlertingRule().Schema, config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.NotifyWhen != "" || rule.Actions[0].Frequency == nil {
+		t.Errorf("Rule level notify_when must not be sent with action frequency, got %+v", rule)
+	}
+}
+
+func TestKibanaAlertingRuleNotifyWhenOnUpdate(t *testing.T) {
+	r := resourceKibanaAlertingRule()
+
+	// The frequency is on state because it's computed, but not on configuration
+	rawConfig, err := ctyjson.Unmarshal([]byte(`{
+		"name": "test",
+		"interval": "1m",
+		"params": "{\"threshold\": 1}",
+		"notify_when": "onActiveAlert",
+		"actions": [{"id": "my-connector"}]
+	}`), r.CoreConfigSchema().ImpliedType())
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := r.Data(&terraform.InstanceState{
+		ID: "test",
+		Attributes: map[string]string{
+			"name":                              "test",
+			"interval":                          "1m",
+			"params":                            `{"threshold": 1}`,
+			"notify_when":                       "onActiveAlert",
+			"actions.#":                         "1",
+			"actions.0.group":                   "default",
+			"actions.0.id":                      "my-connector",
+			"actions.0.params":                  "",
+			"actions.0.frequency.#":             "1",
+			"actions.0.frequency.0.summary":     "false",
+			"actions.0.frequency.0.notify_when": "onThrottleInterval",
+			"actions.0.frequency.0.throttle":    "10m",
+		},
+		RawConfig: rawConfig,
+	})
+
+	// Only rule level notify_when is sent, without the frequency kept on state
+	rule, err := buildAlertingRule(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.NotifyWhen != "onActiveAlert" || rule.Actions[0].Frequency != nil {
+		t.Errorf("Only rule level notify_when must be sent, got %+v", rule)
+	}
+}
+
+func testCheckKibanaAlertingRuleExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No alerting rule ID is set")
+		}
+
+		meta := testAccProvider.Meta()
+
+		client := meta.(*providerMeta).client
+		rule, err := getKibanaAlertingRule(context.Background(), client.Client, rs.Primary.ID, rs.Primary.Attributes["space"])
+		if err != nil {
+			return err
+		}
+		if rule == nil {
+			return errors.Errorf("Alerting rule %s not found", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
+func testCheckKibanaAlertingRuleDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "kibana_alerting_rule" {
+			continue
+		}
+
+		meta := testAccProvider.Meta()
+
+		client := meta.(*providerMeta).client
+		rule, err := getKibanaAlertingRule(context.Background(), client.Client, rs.Primary.ID, rs.Primary.Attributes["space"])
+		if err != nil {
+			return err
+		}
+		if rule == nil {
+			return nil
+		}
+
+		return fmt.Errorf("Alerting rule %q still exists", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+var testKibanaAlertingRule = `
+resource "kibana_alerting_rule" "test" {
+  rule_id      = "terraform-test"
+  name         = "terraform-test"
+  rule_type_id = ".index-threshold"
+  consumer     = "alerts"
+  interval     = "1m"
+  notify_when  = "onActiveAlert"
+  tags         = ["terraform"]
+  params       = jsonencode({
+    index               = ["logstash-terraform-*"]
+    timeField           = "@timestamp"
+    aggType             = "count"
+    groupBy             = "all"
+    timeWindowSize      = 5
+    timeWindowUnit      = "m"
+    thresholdComparator = ">"
+    threshold           = [100]
+  })
+}
+`
+
+var testKibanaAlertingRuleUpdate = `
+resource "kibana_alerting_rule" "test" {
+  rule_id      = "terraform-test"
+  name         = "terraform-test"
+  rule_type_id = ".index-threshold"
+  consumer     = "alerts"
+  interval     = "5m"
+  notify_when  = "onActiveAlert"
+  tags         = ["terraform", "test"]
+  enabled      = false
+  params       = jsonencode({
+    index               = ["logstash-terraform-*"]
+    timeField           = "@timestamp"
+    aggType             = "count"
+    groupBy             = "all"
+    timeWindowSize      = 10
+    timeWindowUnit      = "m"
+    thresholdComparator = ">"
+    threshold           = [100]
+  })
+}
+`
@@ -7,15 +7,21 @@ package kb
 
 import (
 	"context"
-	"fmt"
+	"reflect"
+	"strings"
+	"time"
 
 	kibana "github.com/disaster37/go-kibana-rest/v8"
 	"github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	log "github.com/sirupsen/logrus"
+	"github.com/pkg/errors"
 )
 
+// copyObjectFindPageSize is the number of objects by page when search the new copies on target spaces
+const copyObjectFindPageSize = 1000
+
 // Resource specification to handle kibana save object
 func resourceKibanaCopyObject() *schema.Resource {
 	return &schema.Resource{
@@ -24,6 +30,19 @@ func resourceKibanaCopyObject() *schema.Resource {
 		UpdateContext: resourceKibanaCopyObjectUpdate,
 		DeleteContext: resourceKibanaCopyObjectDelete,
 
+		CustomizeDiff: customizeDiffDefaultSpace("source_space"),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceKibanaCopyObjectImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -33,7 +52,7 @@ func resourceKibanaCopyObject() *schema.Resource {
 			"source_space": {
 				Type:     schema.TypeString,
 				Optional: true,
-				Default:  "default",
+				Computed: true,
 			},
 			"target_spaces": {
 				Type:     schema.TypeSet,
@@ -73,10 +92,23 @@ func resourceKibanaCopyObject() *schema.Resource {
 				Optional: true,
 				Default:  true,
 			},
-			"force_update": {
+			"delete_references": {
 				Type:     schema.TypeBool,
 				Optional: true,
+				Default:  false,
+			},
+			"copied_target_spaces": {
+				Type:     schema.TypeSet,
 				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"force_update": {
+				Type:       schema.TypeBool,
+				Optional:   true,
+				Computed:   true,
+				Deprecated: "Drift on target spaces is now detected when reading the resource, force_update is no more needed",
 			},
 		},
 	}
@@ -85,16 +117,19 @@ func resourceKibanaCopyObject() *schema.Resource {
 // Copy objects in Kibana
 func resourceKibanaCopyObjectCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	name := d.Get("name").(string)
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_copy_object", name, d.Get("source_space").(string))
 
-	err := copyObject(d, meta)
+	err := copyObject(ctx, d, meta)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId(name)
+	if err = d.Set("copied_target_spaces", d.Get("target_spaces")); err != nil {
+		return diag.FromErr(err)
+	}
 
-	log.Infof("Copy objects %s successfully", name)
-	fmt.Printf("[INFO] Copy objects %s successfully", name)
+	tflog.Info(ctx, "Copy objects successfully", map[string]interface{}{"name": name})
 
 	return resourceKibanaCopyObjectRead(ctx, d, meta)
 }
@@ -111,19 +146,30 @@ func resourceKibanaCopyObjectRead(ctx context.Context, d *schema.ResourceData, m
 	createNewCopies := d.Get("create_new_copies").(bool)
 	forceUpdate := d.Get("force_update").(bool)
 
-	log.Debugf("Resource id:  %s", id)
-	log.Debugf("Source space: %s", sourceSpace)
-	log.Debugf("Target spaces: %+v", targetSpaces)
-	log.Debugf("Objects: %+v", objects)
-	log.Debugf("Include reference: %t", includeReference)
-	log.Debugf("Overwrite: %t", overwrite)
-	log.Debugf("CreateNewCopies: %t", createNewCopies)
-	log.Debugf("force_update: %t", forceUpdate)
-
-	// @ TODO
-	// A good when is to check if already exported object is the same that original space
-	// To avoid this hard code, we juste use force_update and overwrite field
-	// It make same result but in bad way on terraform spirit
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_copy_object", d.Id(), sourceSpace)
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	tflog.Debug(ctx, "Target spaces", map[string]interface{}{"target_spaces": targetSpaces})
+	tflog.Debug(ctx, "Objects", map[string]interface{}{"objects": objects})
+	tflog.Debug(ctx, "Include reference", map[string]interface{}{"include_reference": includeReference})
+	tflog.Debug(ctx, "Overwrite", map[string]interface{}{"overwrite": overwrite})
+	tflog.Debug(ctx, "Create new copies", map[string]interface{}{"create_new_copies": createNewCopies})
+	tflog.Debug(ctx, "Force update", map[string]interface{}{"force_update": forceUpdate})
+
+	// Keep all spaces where objects have been copied, so they are deleted even if they drift
+	copiedTargetSpaces := d.Get("copied_target_spaces").(*schema.Set).Union(d.Get("target_spaces").(*schema.Set))
+
+	// We only keep on state the target spaces where objects are the same as on source space.
+	// When objects are copied with new IDs, we can only check that copies exist on target spaces, from their origin ID.
+	// So Terraform plan will show a diff on target_spaces if objects have drifted or are missing.
+	if createNewCopies {
+		targetSpaces, err = getCopiedTargetSpaces(ctx, client, sourceSpace, targetSpaces, objects)
+	} else {
+		targetSpaces, err = getSyncedTargetSpaces(ctx, d, client, sourceSpace, targetSpaces, objects, includeReference)
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	if err = d.Set("name", id); err != nil {
 		return diag.FromErr(err)
@@ -134,6 +180,9 @@ func resourceKibanaCopyObjectRead(ctx context.Context, d *schema.ResourceData, m
 	if err = d.Set("target_spaces", targetSpaces); err != nil {
 		return diag.FromErr(err)
 	}
+	if err = d.Set("copied_target_spaces", copiedTargetSpaces); err != nil {
+		return diag.FromErr(err)
+	}
 	if err = d.Set("object", objects); err != nil {
 		return diag.FromErr(err)
 	}
@@ -150,8 +199,7 @@ func resourceKibanaCopyObjectRead(ctx context.Context, d *schema.ResourceData, m
 		return diag.FromErr(err)
 	}
 
-	log.Infof("Read resource %s successfully", id)
-	fmt.Printf("[INFO] Read resource %s successfully", id)
+	tflog.Info(ctx, "Read resource successfully", map[string]interface{}{"id": id})
 
 	return nil
 }
@@ -159,30 +207,102 @@ func resourceKibanaCopyObjectRead(ctx context.Context, d *schema.ResourceData, m
 // Update existing object in Kibana
 func resourceKibanaCopyObjectUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_copy_object", id, d.Get("source_space").(string))
 
-	err := copyObject(d, meta)
+	err := copyObject(ctx, d, meta)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	log.Infof("Updated resource %s successfully", id)
-	fmt.Printf("[INFO] Updated resource %s successfully", id)
+	// Delete copied objects from spaces that are no more targeted, included the spaces removed from state because they drift
+	oldRaw, newRaw := d.GetChange("target_spaces")
+	copiedTargetSpaces := d.Get("copied_target_spaces").(*schema.Set).Union(oldRaw.(*schema.Set))
+	removedSpaces := convertArrayInterfaceToArrayString(copiedTargetSpaces.Difference(newRaw.(*schema.Set)).List())
+	oldObjects, _ := d.GetChange("object")
+	if len(removedSpaces) > 0 {
+		if err = deleteCopiedObject(ctx, d, meta, removedSpaces, buildCopyObjects(oldObjects.(*schema.Set).List())); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if err = d.Set("copied_target_spaces", newRaw); err != nil {
+		return diag.FromErr(err)
+	}
+
+	tflog.Info(ctx, "Updated resource successfully", map[string]interface{}{"id": id})
 
 	return resourceKibanaCopyObjectRead(ctx, d, meta)
 }
 
-// Delete object in Kibana is not supported
-// It just remove object from state
+// Delete copied objects from target spaces
+// The spaces removed from state because objects drift are cleaned too, the objects not found are skipped
 func resourceKibanaCopyObjectDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	id := d.Id()
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_copy_object", id, d.Get("source_space").(string))
+	targetSpaces := convertArrayInterfaceToArrayString(d.Get("copied_target_spaces").(*schema.Set).Union(d.Get("target_spaces").(*schema.Set)).List())
+	objects := buildCopyObjects(d.Get("object").(*schema.Set).List())
+
+	if err := deleteCopiedObject(ctx, d, meta, targetSpaces, objects); err != nil {
+		return diag.FromErr(err)
+	}
 
 	d.SetId("")
 
-	log.Infof("Delete object in not supported - just removing from state")
-	fmt.Printf("[INFO] Delete object in not supported - just removing from state")
+	tflog.Info(ctx, "Deleted copied objects successfully", map[string]interface{}{"id": id})
 	return nil
 
 }
 
+// Import existing copied objects
+// The ID must be formated as <name>:<source_space>:<target_space>,<target_space>:<type>/<id>,<type>/<id>
+func resourceKibanaCopyObjectImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), ":", 4)
+	if len(parts) != 4 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, errors.Errorf("ID %s must be formated as <name>:<source_space>:<target_space>,<target_space>:<type>/<id>,<type>/<id>", d.Id())
+	}
+	name := parts[0]
+	sourceSpace := parts[1]
+	targetSpaces := strings.Split(parts[2], ",")
+	objects, err := parseImportObjects(parts[3])
+	if err != nil {
+		return nil, err
+	}
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_copy_object", name, sourceSpace)
+
+	d.SetId(name)
+	if err = d.Set("name", name); err != nil {
+		return nil, err
+	}
+	if err = d.Set("source_space", sourceSpace); err != nil {
+		return nil, err
+	}
+	if err = d.Set("target_spaces", targetSpaces); err != nil {
+		return nil, err
+	}
+	if err = d.Set("copied_target_spaces", targetSpaces); err != nil {
+		return nil, err
+	}
+	if err = d.Set("object", objects); err != nil {
+		return nil, err
+	}
+	if err = d.Set("include_reference", true); err != nil {
+		return nil, err
+	}
+	if err = d.Set("overwrite", false); err != nil {
+		return nil, err
+	}
+	// Existing copied objects keep the same IDs, else we can't find them
+	if err = d.Set("create_new_copies", false); err != nil {
+		return nil, err
+	}
+	if err = d.Set("delete_references", false); err != nil {
+		return nil, err
+	}
+
+	tflog.Info(ctx, "Imported copied objects successfully", map[string]interface{}{"name": name})
+
+	return []*schema.ResourceData{d}, nil
+}
+
 // Build list of object to export
 func buildCopyObjects(raws []interface{}) []map[string]string {
 
@@ -199,8 +319,128 @@ func buildCopyObjects(raws []interface{}) []map[string]string {
 	return results
 }
 
+// getSyncedTargetSpaces permit to get the target spaces where the objects are the same as on source space
+func getSyncedTargetSpaces(ctx context.Context, d *schema.ResourceData, client *kibana.Client, sourceSpace string, targetSpaces []string, objects []map[string]string, includeReference bool) ([]string, error) {
+
+	sourceData, err := client.API.KibanaSavedObject.Export(nil, objects, includeReference, sourceSpace)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error when export objects from source space %s", sourceSpace)
+	}
+
+	syncedSpaces := make([]string, 0, len(targetSpaces))
+	for _, targetSpace := range targetSpaces {
+		targetData, err := client.API.KibanaSavedObject.Export(nil, objects, includeReference, targetSpace)
+		if err != nil {
+			// Kibana return bad request when some objects to export not exist
+			if apiErr, ok := err.(kbapi.APIError); ok && (apiErr.Code == 400 || apiErr.Code == 404) {
+				tflog.Debug(ctx, "Objects not found on target space", map[string]interface{}{"target_space": targetSpace})
+				continue
+			}
+			return nil, errors.Wrapf(err, "Error when export objects from target space %s", targetSpace)
+		}
+
+		if !suppressEquivalentNDJSON("", string(sourceData), string(targetData), d) {
+			tflog.Debug(ctx, "Objects have drifted on target space", map[string]interface{}{"target_space": targetSpace})
+			continue
+		}
+
+		syncedSpaces = append(syncedSpaces, targetSpace)
+	}
+
+	return syncedSpaces, nil
+}
+
+// getCopiedTargetSpaces permit to get the target spaces where all objects have been copied as new copies, and not drifted from source space
+func getCopiedTargetSpaces(ctx context.Context, client *kibana.Client, sourceSpace string, targetSpaces []string, objects []map[string]string) ([]string, error) {
+	sourceObjects := make([]map[string]interface{}, len(objects))
+	for i, object := range objects {
+		sourceObject, err := client.API.KibanaSavedObject.Get(object["type"], object["id"], sourceSpace)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error when get %s/%s from source space %s", object["type"], object["id"], sourceSpace)
+		}
+		sourceObjects[i] = sourceObject
+	}
+
+	copiedSpaces := make([]string, 0, len(targetSpaces))
+	for _, targetSpace := range targetSpaces {
+		isCopied := true
+		for i, object := range objects {
+			isFound, err := isObjectCopied(client, object, sourceObjects[i], targetSpace)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Error when search copy of %s/%s on target space %s", object["type"], object["id"], targetSpace)
+			}
+			if !isFound {
+				tflog.Debug(ctx, "Object copy not found or drifted on target space", map[string]interface{}{"target_space": targetSpace, "object_type": object["type"], "object_id": object["id"]})
+				isCopied = false
+				break
+			}
+		}
+
+		if isCopied {
+			copiedSpaces = append(copiedSpaces, targetSpace)
+		}
+	}
+
+	return copiedSpaces, nil
+}
+
+// isObjectCopied permit to check if a copy of object exist on space with the same content as source object
+// Only the attributes are compared, because the ID and the references of copy are new IDs
+func isObjectCopied(client *kibana.Client, object map[string]string, sourceObject map[string]interface{}, space string) (bool, error) {
+	copies, err := findObjectCopies(client, object, space)
+	if err != nil {
+		return false, err
+	}
+
+	for _, copy := range copies {
+		if sourceObject == nil || reflect.DeepEqual(copy["attributes"], sourceObject["attributes"]) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// findObjectCopies permit to get the new copies of object on space
+// The new copies have new ID, and the origin ID is the source object ID
+func findObjectCopies(client *kibana.Client, object map[string]string, space string) ([]map[string]interface{}, error) {
+	copies := make([]map[string]interface{}, 0, 1)
+	for page := 1; ; page++ {
+		res, err := client.API.KibanaSavedObject.Find(object["type"], space, &kbapi.OptionalFindParameters{
+			ObjectsPerPage: copyObjectFindPageSize,
+			Page:           page,
+		})
+		if err != nil {
+			// Kibana return bad request when type is not registered, and not found when space not exist
+			if apiErr, ok := err.(kbapi.APIError); ok && (apiErr.Code == 400 || apiErr.Code == 404) {
+				return copies, nil
+			}
+			return nil, err
+		}
+		if res == nil {
+			return copies, nil
+		}
+
+		savedObjects, _ := res["saved_objects"].([]interface{})
+		for _, savedObject := range savedObjects {
+			m, ok := savedObject.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if originID, _ := m["originId"].(string); originID == object["id"] {
+				copies = append(copies, m)
+			}
+		}
+
+		total, _ := res["total"].(float64)
+		if len(savedObjects) == 0 || page*copyObjectFindPageSize >= int(total) {
+			return copies, nil
+		}
+	}
+}
+
 // Copy objects in Kibana
-func copyObject(d *schema.ResourceData, meta interface{}) error {
+func copyObject(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	name := d.Get("name").(string)
 	sourceSpace := d.Get("source_space").(string)
 	targetSpaces := convertArrayInterfaceToArrayString(d.Get("target_spaces").(*schema.Set).List())
@@ -209,14 +449,13 @@ func copyObject(d *schema.ResourceData, meta interface{}) error {
 	overwrite := d.Get("overwrite").(bool)
 	createNewCopies := d.Get("create_new_copies").(bool)
 
-	log.Debugf("Source space: %s", sourceSpace)
-	log.Debugf("Target spaces: %+v", targetSpaces)
-	log.Debugf("Objects: %+v", objects)
-	log.Debugf("Include reference: %t", includeReference)
-	log.Debugf("Overwrite: %t", overwrite)
-	log.Debugf("CreateNewCopies: %t", createNewCopies)
+	tflog.Debug(ctx, "Target spaces", map[string]interface{}{"target_spaces": targetSpaces})
+	tflog.Debug(ctx, "Objects", map[string]interface{}{"objects": objects})
+	tflog.Debug(ctx, "Include reference", map[string]interface{}{"include_reference": includeReference})
+	tflog.Debug(ctx, "Overwrite", map[string]interface{}{"overwrite": overwrite})
+	tflog.Debug(ctx, "Create new copies", map[string]interface{}{"create_new_copies": createNewCopies})
 
-	client := meta.(*kibana.Client)
+	client := meta.(*providerMeta).clientWithContext(ctx)
 
 	objectsParameter := make([]kbapi.KibanaSpaceObjectParameter, 0, 1)
 	for _, object := range objects {
@@ -239,7 +478,67 @@ func copyObject(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	log.Debugf("Copy object for resource successfully: %s", name)
+	tflog.Debug(ctx, "Copy object for resource successfully", map[string]interface{}{"name": name})
+
+	return nil
+}
+
+// Delete copied objects from the provided spaces
+func deleteCopiedObject(ctx context.Context, d *schema.ResourceData, meta interface{}, spaces []string, objects []map[string]string) error {
+	sourceSpace := d.Get("source_space").(string)
+	createNewCopies := d.Get("create_new_copies").(bool)
+	deleteReferences := d.Get("delete_references").(bool)
+
+	tflog.Debug(ctx, "Spaces", map[string]interface{}{"spaces": spaces})
+	tflog.Debug(ctx, "Objects", map[string]interface{}{"objects": objects})
+	tflog.Debug(ctx, "Delete references", map[string]interface{}{"delete_references": deleteReferences})
+
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	// Get the references from source space, they have been copied with objects
+	if deleteReferences {
+		data, err := client.API.KibanaSavedObject.Export(nil, objects, true, sourceSpace)
+		if err != nil {
+			if apiErr, ok := err.(kbapi.APIError); !ok || (apiErr.Code != 400 && apiErr.Code != 404) {
+				return errors.Wrapf(err, "Error when export references from source space %s", sourceSpace)
+			}
+			tflog.Warn(ctx, "Objects not found on source space - only delete objects without references", map[string]interface{}{"source_space": sourceSpace})
+		} else {
+			if objects, err = parseNDJSONObjects(string(data)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, space := range spaces {
+		spaceObjects := objects
+
+		// Copied objects get new IDs, so we search them from their origin ID
+		if createNewCopies {
+			spaceObjects = make([]map[string]string, 0, len(objects))
+			for _, object := range objects {
+				copies, err := findObjectCopies(client, object, space)
+				if err != nil {
+					return errors.Wrapf(err, "Error when search copies of %s/%s on space %s", object["type"], object["id"], space)
+				}
+				for _, copy := range copies {
+					copyID, _ := copy["id"].(string)
+					spaceObjects = append(spaceObjects, map[string]string{"type": object["type"], "id": copyID})
+				}
+			}
+		}
+
+		for _, object := range spaceObjects {
+			if err := client.API.KibanaSavedObject.Delete(object["type"], object["id"], space); err != nil {
+				if apiErr, ok := err.(kbapi.APIError); ok && apiErr.Code == 404 {
+					tflog.Warn(ctx, "Object not found on space - skip it", map[string]interface{}{"object_type": object["type"], "object_id": object["id"], "space": space})
+					continue
+				}
+				return errors.Wrapf(err, "Error when delete object %s/%s on space %s", object["type"], object["id"], space)
+			}
+			tflog.Debug(ctx, "Deleted object on space successfully", map[string]interface{}{"object_type": object["type"], "object_id": object["id"], "space": space})
+		}
+	}
 
 	return nil
 }
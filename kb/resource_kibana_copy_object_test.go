@@ -1,16 +1,20 @@
 package kb
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
-	kibana "github.com/disaster37/go-kibana-rest/v8"
 	"github.com/disaster37/go-kibana-rest/v8/kbapi"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
 )
 
 func TestAccKibanaCopyObject(t *testing.T) {
@@ -28,8 +32,207 @@ func TestAccKibanaCopyObject(t *testing.T) {
 					testCheckKibanaCopyObjectExists("kibana_copy_object.test"),
 				),
 			},
+			{
+				ResourceName:            "kibana_copy_object.test",
+				ImportState:             true,
+				ImportStateId:           "terraform-test2:default:terraform-test2:index-pattern/test",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"overwrite"},
+			},
+			{
+				// Remove copied object on target space to check drift is detected
+				PreConfig: func() {
+					client := testAccProvider.Meta().(*providerMeta).client
+					if err := client.API.KibanaSavedObject.Delete("index-pattern", "test", "terraform-test2"); err != nil {
+						panic(err)
+					}
+				},
+				Config:             getTestKibanaCopyObject(),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				// Copy again the object on target space
+				Config: getTestKibanaCopyObject(),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckKibanaCopyObjectExists("kibana_copy_object.test"),
+				),
+			},
+			{
+				// Remove only the copy, while the target space still exists
+				Config: getTestKibanaCopyObjectWithoutCopy(),
+				Check:  testCheckKibanaCopyObjectDeleted("index-pattern", "test", "terraform-test2"),
+			},
+		},
+	})
+}
+
+func TestAccKibanaCopyObjectNewCopies(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckKibanaCopyObjectDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: getTestKibanaCopyObjectNewCopies(),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckKibanaCopyObjectExists("kibana_copy_object.test"),
+				),
+			},
+			{
+				// Remove the new copies, while the target space still exists
+				Config: getTestKibanaCopyObjectWithoutCopy(),
+				Check:  testCheckKibanaCopyObjectCopiesDeleted("index-pattern", "test", "terraform-test2"),
+			},
+		},
+	})
+}
+
+// testCheckKibanaCopyObjectDeleted check the copied object is deleted from target space
+func testCheckKibanaCopyObjectDeleted(objectType string, objectID string, targetSpace string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*providerMeta).client
+
+		space, err := client.API.KibanaSpaces.Get(targetSpace)
+		if err != nil {
+			return err
+		}
+		if space == nil {
+			return errors.Errorf("Target space %s must still exist", targetSpace)
+		}
+
+		object, err := client.API.KibanaSavedObject.Get(objectType, objectID, targetSpace)
+		if err != nil {
+			return err
+		}
+		if object != nil {
+			return fmt.Errorf("Copied object %s/%s still exists on space %s", objectType, objectID, targetSpace)
+		}
+
+		return nil
+	}
+}
+
+// testCheckKibanaCopyObjectCopiesDeleted check the new copies of object are deleted from target space
+func testCheckKibanaCopyObjectCopiesDeleted(objectType string, objectID string, targetSpace string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		client := testAccProvider.Meta().(*providerMeta).client
+
+		copies, err := findObjectCopies(client, map[string]string{"type": objectType, "id": objectID}, targetSpace)
+		if err != nil {
+			return err
+		}
+		if len(copies) > 0 {
+			return fmt.Errorf("Copies of %s/%s still exist on space %s", objectType, objectID, targetSpace)
+		}
+
+		return nil
+	}
+}
+
+func TestResourceKibanaCopyObjectDelete(t *testing.T) {
+	calls := []string{}
+	meta := newTestProviderMeta(t, func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		if strings.HasPrefix(r.URL.Path, "/s/drifted/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceKibanaCopyObject().Schema, map[string]interface{}{
+		"name":                 "test",
+		"source_space":         "default",
+		"target_spaces":        []interface{}{"synced"},
+		"copied_target_spaces": []interface{}{"synced", "drifted"},
+		"create_new_copies":    false,
+		"object": []interface{}{
+			map[string]interface{}{"id": "test", "type": "index-pattern"},
+		},
+	})
+	d.SetId("test")
+
+	// The space removed from state because object drift is cleaned too, and not found object is skipped
+	if diags := resourceKibanaCopyObjectDelete(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("Unexpected error: %+v", diags)
+	}
+	sort.Strings(calls)
+	expected := []string{
+		"DELETE /s/drifted/api/saved_objects/index-pattern/test",
+		"DELETE /s/synced/api/saved_objects/index-pattern/test",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, calls)
+	}
+}
+
+func TestResourceKibanaCopyObjectDeleteNewCopies(t *testing.T) {
+	calls := []string{}
+	meta := newTestProviderMeta(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"page": 1, "per_page": 1000, "total": 2, "saved_objects": [{"id": "other", "type": "index-pattern"}, {"id": "new-id", "type": "index-pattern", "originId": "test"}]}`))
+			return
+		}
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceKibanaCopyObject().Schema, map[string]interface{}{
+		"name":              "test",
+		"source_space":      "default",
+		"target_spaces":     []interface{}{"copied"},
+		"create_new_copies": true,
+		"object": []interface{}{
+			map[string]interface{}{"id": "test", "type": "index-pattern"},
 		},
 	})
+	d.SetId("test")
+
+	// Only the copy found by its origin ID is deleted
+	if diags := resourceKibanaCopyObjectDelete(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("Unexpected error: %+v", diags)
+	}
+	expected := []string{
+		"DELETE /s/copied/api/saved_objects/index-pattern/new-id",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, calls)
+	}
+}
+
+func TestGetCopiedTargetSpaces(t *testing.T) {
+	meta := newTestProviderMeta(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/saved_objects/index-pattern/test":
+			_, _ = w.Write([]byte(`{"id": "test", "type": "index-pattern", "attributes": {"title": "test"}}`))
+		case strings.HasPrefix(r.URL.Path, "/s/copied/") && r.URL.Query().Get("page") == "1":
+			_, _ = w.Write([]byte(`{"page": 1, "per_page": 1000, "total": 1001, "saved_objects": [{"id": "other", "type": "index-pattern", "attributes": {"title": "test"}}]}`))
+		case strings.HasPrefix(r.URL.Path, "/s/copied/"):
+			_, _ = w.Write([]byte(`{"page": 2, "per_page": 1000, "total": 1001, "saved_objects": [{"id": "new-id", "type": "index-pattern", "originId": "test", "attributes": {"title": "test"}}]}`))
+		case strings.HasPrefix(r.URL.Path, "/s/drifted/"):
+			_, _ = w.Write([]byte(`{"page": 1, "per_page": 1000, "total": 1, "saved_objects": [{"id": "new-id", "type": "index-pattern", "originId": "test", "attributes": {"title": "drifted"}}]}`))
+		case strings.HasPrefix(r.URL.Path, "/s/unknown/"):
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			_, _ = w.Write([]byte(`{"page": 1, "per_page": 1000, "total": 1, "saved_objects": [{"id": "other", "type": "index-pattern"}]}`))
+		}
+	})
+
+	// Only the spaces where the copy is found by its origin ID, with the same attributes as source, are kept
+	spaces, err := getCopiedTargetSpaces(context.Background(), meta.client, "default", []string{"copied", "drifted", "missing", "unknown"}, []map[string]string{{"type": "index-pattern", "id": "test"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(spaces, []string{"copied"}) {
+		t.Errorf("Expected only copied space, got %+v", spaces)
+	}
 }
 
 func testCheckKibanaCopyObjectExists(name string) resource.TestCheckFunc {
@@ -49,7 +252,7 @@ func testCheckKibanaCopyObjectExists(name string) resource.TestCheckFunc {
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*kibana.Client)
+		client := meta.(*providerMeta).client
 		data, err := client.API.KibanaSavedObject.Find(objectType, targetSpace, &kbapi.OptionalFindParameters{
 			Search: fmt.Sprintf("originId:\"%s\"", objectID),
 		})
@@ -71,14 +274,43 @@ func testCheckKibanaCopyObjectDestroy(s *terraform.State) error {
 			continue
 		}
 
-		log.Warn("We never delete kibana object")
+		meta := testAccProvider.Meta()
 
+		client := meta.(*providerMeta).client
+		object, err := client.API.KibanaSavedObject.Get("index-pattern", "test", "terraform-test2")
+		if err != nil {
+			return err
+		}
+		if object != nil {
+			return fmt.Errorf("Copied object %q still exists", rs.Primary.ID)
+		}
 	}
 
 	return nil
 
 }
 
+func getTestKibanaCopyObjectWithoutCopy() string {
+	path, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+
+	return fmt.Sprintf(`
+resource kibana_object "test" {
+  name 				= "terraform-test"
+  data				= file("%s/../fixtures/test.ndjson")
+  deep_reference	= "true"
+  export_types    	= ["index-pattern"]
+}
+
+resource kibana_user_space "test" {
+  uid 				= "terraform-test2"
+  name				= "terraform-test2"
+}
+`, path)
+}
+
 func getTestKibanaCopyObject() string {
 	path, err := os.Getwd()
 	if err != nil {
@@ -114,3 +346,38 @@ resource kibana_copy_object "test" {
 `, path)
 
 }
+
+func getTestKibanaCopyObjectNewCopies() string {
+	path, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+
+	return fmt.Sprintf(`
+resource kibana_object "test" {
+  name 				= "terraform-test"
+  data				= file("%s/../fixtures/test.ndjson")
+  deep_reference	= "true"
+  export_types    	= ["index-pattern"]
+}
+
+resource kibana_user_space "test" {
+  uid 				= "terraform-test2"
+  name				= "terraform-test2"
+}
+
+resource kibana_copy_object "test" {
+  name 				= "terraform-test2"
+  source_space		= "default"
+  target_spaces		= ["${kibana_user_space.test.uid}"]
+  object {
+	  id   = "test"
+	  type = "index-pattern"
+  }
+  create_new_copies = true
+
+  depends_on = [kibana_object.test, kibana_user_space.test]
+}
+`, path)
+
+}
@@ -0,0 +1,508 @@
+// Manage the data view in Kibana
+// API documentation: https://www.elastic.co/guide/en/kibana/master/data-views-api.html
+// Supported version:
+//  - v8
+
+package kb
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	kbapi "github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// Resource specification to handle data view in Kibana
+func resourceKibanaDataView() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceKibanaDataViewCreate,
+		ReadContext:   resourceKibanaDataViewRead,
+		UpdateContext: resourceKibanaDataViewUpdate,
+		DeleteContext: resourceKibanaDataViewDelete,
+
+		CustomizeDiff: customizeDiffDefaultSpace("space"),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceKibanaDataViewImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"data_view_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+			},
+			"space": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Computed: true,
+			},
+			"title": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"time_field_name": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"source_filters": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"field_formats": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"field": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"id": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"params": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							DiffSuppressFunc: suppressEquivalentJSON,
+						},
+					},
+				},
+			},
+			"runtime_field_map": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"script_source": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"field_attrs": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"field": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"custom_label": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"count": {
+							Type:     schema.TypeInt,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"allow_no_index": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"namespaces": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Computed: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+// Create new data view in Kibana
+func resourceKibanaDataViewCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	space := d.Get("space").(string)
+
+	dataView, err := buildDataView(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	dataView.ID = d.Get("data_view_id").(string)
+	dataView.Namespaces = convertArrayInterfaceToArrayString(d.Get("namespaces").(*schema.Set).List())
+
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_data_view", d.Id(), space)
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	dataView, err = createKibanaDataView(ctx, client.Client, dataView, space)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(dataView.ID)
+
+	tflog.Info(ctx, "Created data view successfully", map[string]interface{}{"id": dataView.ID})
+
+	return resourceKibanaDataViewRead(ctx, d, meta)
+}
+
+// Read existing data view in Kibana
+func resourceKibanaDataViewRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+
+	var err error
+	id := d.Id()
+	space := d.Get("space").(string)
+
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_data_view", d.Id(), space)
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	dataView, err := getKibanaDataView(ctx, client.Client, id, space)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if dataView == nil {
+		tflog.Warn(ctx, "Data view not found - removing from state", map[string]interface{}{"id": id})
+		d.SetId("")
+		return nil
+	}
+
+	tflog.Debug(ctx, "Get data view successfully", map[string]interface{}{"id": id, "data_view": dataView.String()})
+
+	if err = d.Set("data_view_id", dataView.ID); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("space", space); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("title", dataView.Title); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("name", dataView.Name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("time_field_name", dataView.TimeFieldName); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("source_filters", flattenDataViewSourceFilters(dataView.SourceFilters)); err != nil {
+		return diag.FromErr(err)
+	}
+	flattenFieldFormats, err := flattenDataViewFieldFormats(dataView.FieldFormats)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("field_formats", flattenFieldFormats); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("runtime_field_map", flattenDataViewRuntimeFieldMap(dataView.RuntimeFieldMap)); err != nil {
+		return diag.FromErr(err)
+	}
+	managedFields := buildDataViewFieldAttrsMap(d.Get("field_attrs").(*schema.Set).List())
+	if err = d.Set("field_attrs", flattenDataViewFieldAttrs(dataView.FieldAttrs, managedFields)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("allow_no_index", dataView.AllowNoIndex); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("namespaces", dataView.Namespaces); err != nil {
+		return diag.FromErr(err)
+	}
+
+	tflog.Info(ctx, "Read data view successfully", map[string]interface{}{"id": id})
+
+	return nil
+}
+
+// Update existing data view in Kibana
+func resourceKibanaDataViewUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	id := d.Id()
+	space := d.Get("space").(string)
+
+	dataView, err := buildDataView(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	dataView.ID = id
+
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_data_view", d.Id(), space)
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	if err = updateKibanaDataView(ctx, client.Client, dataView, space); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Field attributes are not handled by update API
+	if d.HasChange("field_attrs") {
+		oldRaw, newRaw := d.GetChange("field_attrs")
+		fields := map[string]*kibanaDataViewFieldAttr{}
+		for _, field := range buildDataViewFieldAttrs(oldRaw.(*schema.Set).List()) {
+			fields[field] = nil
+		}
+		for field, fieldAttr := range buildDataViewFieldAttrsMap(newRaw.(*schema.Set).List()) {
+			fieldAttr := fieldAttr
+			fields[field] = &fieldAttr
+		}
+		if err = updateKibanaDataViewFields(ctx, client.Client, id, fields, space); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	tflog.Info(ctx, "Updated data view successfully", map[string]interface{}{"id": id})
+
+	return resourceKibanaDataViewRead(ctx, d, meta)
+}
+
+// Delete existing data view in Kibana
+func resourceKibanaDataViewDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+
+	id := d.Id()
+	space := d.Get("space").(string)
+
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_data_view", d.Id(), space)
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	if err := deleteKibanaDataView(ctx, client.Client, id, space); err != nil {
+		if apiErr, ok := err.(kbapi.APIError); ok && apiErr.Code == 404 {
+			tflog.Warn(ctx, "Data view not found - removing from state", map[string]interface{}{"id": id})
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+
+	tflog.Info(ctx, "Deleted data view successfully", map[string]interface{}{"id": id})
+	return nil
+
+}
+
+// Import existing data view
+// The ID must be formated as <space>:<data_view_id>
+func resourceKibanaDataViewImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, errors.Errorf("ID %s must be formated as <space>:<data_view_id>", d.Id())
+	}
+
+	d.SetId(parts[1])
+	if err := d.Set("space", parts[0]); err != nil {
+		return nil, err
+	}
+	if err := d.Set("allow_no_index", false); err != nil {
+		return nil, err
+	}
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// buildDataView permit to build data view from resource data
+func buildDataView(d *schema.ResourceData) (*kibanaDataView, error) {
+	dataView := &kibanaDataView{
+		Title:         d.Get("title").(string),
+		Name:          d.Get("name").(string),
+		TimeFieldName: d.Get("time_field_name").(string),
+		AllowNoIndex:  d.Get("allow_no_index").(bool),
+	}
+
+	for _, sourceFilter := range convertArrayInterfaceToArrayString(d.Get("source_filters").([]interface{})) {
+		dataView.SourceFilters = append(dataView.SourceFilters, kibanaDataViewSourceFilter{Value: sourceFilter})
+	}
+
+	fieldFormats, err := buildDataViewFieldFormats(d.Get("field_formats").(*schema.Set).List())
+	if err != nil {
+		return nil, err
+	}
+	dataView.FieldFormats = fieldFormats
+	dataView.RuntimeFieldMap = buildDataViewRuntimeFieldMap(d.Get("runtime_field_map").(*schema.Set).List())
+	dataView.FieldAttrs = buildDataViewFieldAttrsMap(d.Get("field_attrs").(*schema.Set).List())
+
+	return dataView, nil
+}
+
+// buildDataViewFieldFormats permit to build field formats map
+func buildDataViewFieldFormats(raws []interface{}) (map[string]kibanaDataViewFieldFormat, error) {
+	if len(raws) == 0 {
+		return nil, nil
+	}
+
+	fieldFormats := make(map[string]kibanaDataViewFieldFormat, len(raws))
+	for _, raw := range raws {
+		m := raw.(map[string]interface{})
+		fieldFormat := kibanaDataViewFieldFormat{
+			ID: m["id"].(string),
+		}
+		if params := optionalInterfaceJSON(m["params"].(string)); params != nil {
+			if err := json.Unmarshal(params.(json.RawMessage), &fieldFormat.Params); err != nil {
+				return nil, errors.Wrapf(err, "Error when unmarshal params of field format %s", m["field"].(string))
+			}
+		}
+		fieldFormats[m["field"].(string)] = fieldFormat
+	}
+
+	return fieldFormats, nil
+}
+
+// buildDataViewRuntimeFieldMap permit to build runtime fields map
+func buildDataViewRuntimeFieldMap(raws []interface{}) map[string]kibanaDataViewRuntimeField {
+	if len(raws) == 0 {
+		return nil
+	}
+
+	runtimeFields := make(map[string]kibanaDataViewRuntimeField, len(raws))
+	for _, raw := range raws {
+		m := raw.(map[string]interface{})
+		runtimeField := kibanaDataViewRuntimeField{
+			Type: m["type"].(string),
+		}
+		if m["script_source"].(string) != "" {
+			runtimeField.Script = &kibanaDataViewRuntimeFieldScript{
+				Source: m["script_source"].(string),
+			}
+		}
+		runtimeFields[m["name"].(string)] = runtimeField
+	}
+
+	return runtimeFields
+}
+
+// buildDataViewFieldAttrsMap permit to build field attributes map
+func buildDataViewFieldAttrsMap(raws []interface{}) map[string]kibanaDataViewFieldAttr {
+	if len(raws) == 0 {
+		return nil
+	}
+
+	fieldAttrs := make(map[string]kibanaDataViewFieldAttr, len(raws))
+	for _, raw := range raws {
+		m := raw.(map[string]interface{})
+		fieldAttrs[m["field"].(string)] = kibanaDataViewFieldAttr{
+			CustomLabel: m["custom_label"].(string),
+			Count:       m["count"].(int),
+		}
+	}
+
+	return fieldAttrs
+}
+
+// buildDataViewFieldAttrs permit to get the list of fields that have attributes
+func buildDataViewFieldAttrs(raws []interface{}) []string {
+	fields := make([]string, 0, len(raws))
+	for _, raw := range raws {
+		fields = append(fields, raw.(map[string]interface{})["field"].(string))
+	}
+
+	return fields
+}
+
+func flattenDataViewSourceFilters(sourceFilters []kibanaDataViewSourceFilter) []interface{} {
+	if sourceFilters == nil {
+		return nil
+	}
+
+	tfList := make([]interface{}, 0, len(sourceFilters))
+	for _, sourceFilter := range sourceFilters {
+		tfList = append(tfList, sourceFilter.Value)
+	}
+
+	return tfList
+}
+
+func flattenDataViewFieldFormats(fieldFormats map[string]kibanaDataViewFieldFormat) ([]interface{}, error) {
+	if fieldFormats == nil {
+		return nil, nil
+	}
+
+	tfList := make([]interface{}, 0, len(fieldFormats))
+	for field, fieldFormat := range fieldFormats {
+		params, err := convertInterfaceToJsonString(fieldFormat.Params)
+		if err != nil {
+			return nil, err
+		}
+		tfList = append(tfList, map[string]interface{}{
+			"field":  field,
+			"id":     fieldFormat.ID,
+			"params": params,
+		})
+	}
+
+	return tfList, nil
+}
+
+func flattenDataViewRuntimeFieldMap(runtimeFields map[string]kibanaDataViewRuntimeField) []interface{} {
+	if runtimeFields == nil {
+		return nil
+	}
+
+	tfList := make([]interface{}, 0, len(runtimeFields))
+	for name, runtimeField := range runtimeFields {
+		tfMap := map[string]interface{}{
+			"name": name,
+			"type": runtimeField.Type,
+		}
+		if runtimeField.Script != nil {
+			tfMap["script_source"] = runtimeField.Script.Source
+		}
+		tfList = append(tfList, tfMap)
+	}
+
+	return tfList
+}
+
+// flattenDataViewFieldAttrs keep only the managed fields or fields with custom label
+// Kibana increment the count of fields when they are used, so the count is only kept when it's managed, to not show it as drift
+func flattenDataViewFieldAttrs(fieldAttrs map[string]kibanaDataViewFieldAttr, managedFields map[string]kibanaDataViewFieldAttr) []interface{} {
+	if fieldAttrs == nil {
+		return nil
+	}
+
+	tfList := make([]interface{}, 0, len(fieldAttrs))
+	for field, fieldAttr := range fieldAttrs {
+		managedField, isManaged := managedFields[field]
+		if !isManaged && fieldAttr.CustomLabel == "" {
+			continue
+		}
+
+		count := 0
+		if managedField.Count != 0 {
+			count = fieldAttr.Count
+		}
+		tfList = append(tfList, map[string]interface{}{
+			"field":        field,
+			"custom_label": fieldAttr.CustomLabel,
+			"count":        count,
+		})
+	}
+
+	return tfList
+}
@@ -0,0 +1,175 @@
+package kb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/pkg/errors"
+)
+
+func TestAccKibanaDataView(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckKibanaDataViewDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testKibanaDataView,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckKibanaDataViewExists("kibana_data_view.test"),
+					resource.TestCheckResourceAttr("kibana_data_view.test", "name", "terraform-test"),
+					resource.TestCheckResourceAttr("kibana_data_view.test", "source_filters.#", "1"),
+				),
+			},
+			{
+				Config: testKibanaDataViewUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckKibanaDataViewExists("kibana_data_view.test"),
+					resource.TestCheckResourceAttr("kibana_data_view.test", "time_field_name", "@timestamp"),
+					resource.TestCheckResourceAttr("kibana_data_view.test", "field_attrs.#", "1"),
+				),
+			},
+			{
+				ResourceName:      "kibana_data_view.test",
+				ImportState:       true,
+				ImportStateId:     "default:terraform-test",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestKibanaDataViewFlattenFieldAttrs(t *testing.T) {
+	fieldAttrs := map[string]kibanaDataViewFieldAttr{
+		"message":    {CustomLabel: "Message", Count: 3},
+		"host.name":  {Count: 5},
+		"user.name":  {Count: 2},
+		"event.code": {CustomLabel: "Code", Count: 4},
+	}
+	managedFields := map[string]kibanaDataViewFieldAttr{
+		"user.name":  {Count: 1},
+		"event.code": {CustomLabel: "Code"},
+	}
+
+	// Only managed fields and fields with custom label are kept, and the count only when it's managed
+	tfList := flattenDataViewFieldAttrs(fieldAttrs, managedFields)
+	result := map[string]interface{}{}
+	for _, raw := range tfList {
+		result[raw.(map[string]interface{})["field"].(string)] = raw
+	}
+	expected := map[string]interface{}{
+		"message": map[string]interface{}{
+			"field":        "message",
+			"custom_label": "Message",
+			"count":        0,
+		},
+		"user.name": map[string]interface{}{
+			"field":        "user.name",
+			"custom_label": "",
+			"count":        2,
+		},
+		"event.code": map[string]interface{}{
+			"field":        "event.code",
+			"custom_label": "Code",
+			"count":        0,
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func testCheckKibanaDataViewExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No data view ID is set")
+		}
+
+		meta := testAccProvider.Meta()
+
+		client := meta.(*providerMeta).client
+		dataView, err := getKibanaDataView(context.Background(), client.Client, rs.Primary.ID, rs.Primary.Attributes["space"])
+		if err != nil {
+			return err
+		}
+		if dataView == nil {
+			return errors.Errorf("Data view %s not found", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
+func testCheckKibanaDataViewDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "kibana_data_view" {
+			continue
+		}
+
+		meta := testAccProvider.Meta()
+
+		client := meta.(*providerMeta).client
+		dataView, err := getKibanaDataView(context.Background(), client.Client, rs.Primary.ID, rs.Primary.Attributes["space"])
+		if err != nil {
+			return err
+		}
+		if dataView == nil {
+			return nil
+		}
+
+		return fmt.Errorf("Data view %q still exists", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+var testKibanaDataView = `
+resource "kibana_data_view" "test" {
+  data_view_id   = "terraform-test"
+  title          = "logstash-terraform-*"
+  name           = "terraform-test"
+  allow_no_index = true
+  source_filters = ["password"]
+
+  field_formats {
+    field  = "bytes"
+    id     = "bytes"
+    params = jsonencode({
+      pattern = "0,0.[000]b"
+    })
+  }
+
+  runtime_field_map {
+    name          = "hour_of_day"
+    type          = "long"
+    script_source = "emit(doc['@timestamp'].value.getHour());"
+  }
+}
+`
+
+var testKibanaDataViewUpdate = `
+resource "kibana_data_view" "test" {
+  data_view_id    = "terraform-test"
+  title           = "logstash-terraform-*"
+  name            = "terraform-test"
+  time_field_name = "@timestamp"
+  allow_no_index  = true
+  source_filters  = ["password", "secret"]
+
+  field_attrs {
+    field        = "message"
+    custom_label = "Message"
+  }
+}
+`
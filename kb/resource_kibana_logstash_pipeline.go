@@ -7,13 +7,12 @@ package kb
 
 import (
 	"context"
-	"fmt"
+	"time"
 
-	kibana "github.com/disaster37/go-kibana-rest/v8"
 	kbapi "github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	log "github.com/sirupsen/logrus"
 )
 
 // Resource specification to handle logstash pipeline in Kibana
@@ -28,6 +27,13 @@ func resourceKibanaLogstashPipeline() *schema.Resource {
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -93,15 +99,16 @@ func resourceKibanaLogstashPipeline() *schema.Resource {
 // Create new logstash pipeline in Kibana
 func resourceKibanaLogstashPipelineCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 
-	logstashPipeline, err := createOrUpdateLogstashPipeline(d, meta)
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_logstash_pipeline", d.Get("name").(string), "")
+
+	logstashPipeline, err := createOrUpdateLogstashPipeline(ctx, d, meta)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId(logstashPipeline.ID)
 
-	log.Infof("Created logstash pipeline %s successfully", logstashPipeline.ID)
-	fmt.Printf("[INFO] Created logstash pipeline %s successfully", logstashPipeline.ID)
+	tflog.Info(ctx, "Created logstash pipeline successfully", map[string]interface{}{"id": logstashPipeline.ID})
 
 	return resourceKibanaLogstashPipelineRead(ctx, d, meta)
 }
@@ -112,9 +119,8 @@ func resourceKibanaLogstashPipelineRead(ctx context.Context, d *schema.ResourceD
 	var err error
 	id := d.Id()
 
-	log.Debugf("Logstash pipeline id:  %s", id)
-
-	client := meta.(*kibana.Client)
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_logstash_pipeline", d.Id(), "")
+	client := meta.(*providerMeta).clientWithContext(ctx)
 
 	logstashPiepeline, err := client.API.KibanaLogstashPipeline.Get(id)
 	if err != nil {
@@ -122,13 +128,12 @@ func resourceKibanaLogstashPipelineRead(ctx context.Context, d *schema.ResourceD
 	}
 
 	if logstashPiepeline == nil {
-		log.Warnf("Logstash piepline %s not found - removing from state", id)
-		fmt.Printf("[WARN] Logstash piepline %s not found - removing from state", id)
+		tflog.Warn(ctx, "Logstash pipeline not found - removing from state", map[string]interface{}{"id": id})
 		d.SetId("")
 		return nil
 	}
 
-	log.Debugf("Get logstash piepeline %s successfully:\n%s", id, logstashPiepeline)
+	tflog.Debug(ctx, "Get logstash pipeline successfully", map[string]interface{}{"id": id, "logstash_pipeline": logstashPiepeline.String()})
 
 	if err = d.Set("name", logstashPiepeline.ID); err != nil {
 		return diag.FromErr(err)
@@ -164,8 +169,7 @@ func resourceKibanaLogstashPipelineRead(ctx context.Context, d *schema.ResourceD
 
 	}
 
-	log.Infof("Read logstash pipeline %s successfully", id)
-	fmt.Printf("[INFO] Read logstash pipeline %s successfully", id)
+	tflog.Info(ctx, "Read logstash pipeline successfully", map[string]interface{}{"id": id})
 
 	return nil
 }
@@ -173,13 +177,14 @@ func resourceKibanaLogstashPipelineRead(ctx context.Context, d *schema.ResourceD
 // Update existing logstash pipeline in Elasticsearch
 func resourceKibanaLogstashPipelineUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 
-	logstashPipeline, err := createOrUpdateLogstashPipeline(d, meta)
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_logstash_pipeline", d.Id(), "")
+
+	logstashPipeline, err := createOrUpdateLogstashPipeline(ctx, d, meta)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	log.Infof("Updated logstash piepeline %s successfully", logstashPipeline.ID)
-	fmt.Printf("[INFO] Updated logstash piepeline %s successfully", logstashPipeline.ID)
+	tflog.Info(ctx, "Updated logstash pipeline successfully", map[string]interface{}{"id": logstashPipeline.ID})
 
 	return resourceKibanaLogstashPipelineRead(ctx, d, meta)
 }
@@ -188,14 +193,13 @@ func resourceKibanaLogstashPipelineUpdate(ctx context.Context, d *schema.Resourc
 func resourceKibanaLogstashPipelineDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 
 	id := d.Id()
-	log.Debugf("Logstash pipeline id: %s", id)
 
-	client := meta.(*kibana.Client)
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_logstash_pipeline", d.Id(), "")
+	client := meta.(*providerMeta).clientWithContext(ctx)
 
 	if err := client.API.KibanaLogstashPipeline.Delete(id); err != nil {
 		if err.(kbapi.APIError).Code == 404 {
-			log.Warnf("Logstash pipeline %s not found - removing from state", id)
-			fmt.Printf("[WARN] Logstash pipeline %s not found - removing from state", id)
+			tflog.Warn(ctx, "Logstash pipeline not found - removing from state", map[string]interface{}{"id": id})
 			d.SetId("")
 			return nil
 		}
@@ -205,20 +209,19 @@ func resourceKibanaLogstashPipelineDelete(ctx context.Context, d *schema.Resourc
 
 	d.SetId("")
 
-	log.Infof("Deleted logstash pipeline %s successfully", id)
-	fmt.Printf("[INFO] Deleted logstash pipeline %s successfully", id)
+	tflog.Info(ctx, "Deleted logstash pipeline successfully", map[string]interface{}{"id": id})
 	return nil
 
 }
 
 // createOrUpdateLogstashPipeline permit to create or update logstash pipeline
-func createOrUpdateLogstashPipeline(d *schema.ResourceData, meta interface{}) (*kbapi.LogstashPipeline, error) {
+func createOrUpdateLogstashPipeline(ctx context.Context, d *schema.ResourceData, meta interface{}) (*kbapi.LogstashPipeline, error) {
 	name := d.Get("name").(string)
 	description := d.Get("description").(string)
 	pipeline := d.Get("pipeline").(string)
 	settings := d.Get("settings").(*schema.Set).List()
 
-	client := meta.(*kibana.Client)
+	client := meta.(*providerMeta).clientWithContext(ctx)
 
 	logstashPipeline := &kbapi.LogstashPipeline{
 		ID:          name,
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"testing"
 
-	kibana "github.com/disaster37/go-kibana-rest/v8"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/pkg/errors"
@@ -47,7 +46,7 @@ func testCheckKibanaLogstashPipelineExists(name string) resource.TestCheckFunc {
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*kibana.Client)
+		client := meta.(*providerMeta).client
 		logstashPipeline, err := client.API.KibanaLogstashPipeline.Get(rs.Primary.ID)
 		if err != nil {
 			return err
@@ -68,7 +67,7 @@ func testCheckKibanaLogstashPipelineDestroy(s *terraform.State) error {
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*kibana.Client)
+		client := meta.(*providerMeta).client
 		logstashPipeline, err := client.API.KibanaLogstashPipeline.Get(rs.Primary.ID)
 		if err != nil {
 			return err
@@ -7,12 +7,16 @@ package kb
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
+	"strings"
+	"time"
 
 	kibana "github.com/disaster37/go-kibana-rest/v8"
+	"github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	log "github.com/sirupsen/logrus"
+	"github.com/pkg/errors"
 )
 
 // Resource specification to handle kibana save object
@@ -23,6 +27,19 @@ func resourceKibanaObject() *schema.Resource {
 		UpdateContext: resourceKibanaObjectUpdate,
 		DeleteContext: resourceKibanaObjectDelete,
 
+		CustomizeDiff: customizeDiffDefaultSpace("space"),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceKibanaObjectImport,
+		},
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(20 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(20 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -33,7 +50,7 @@ func resourceKibanaObject() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 				ForceNew: true,
-				Default:  "default",
+				Computed: true,
 			},
 			"data": {
 				Type:             schema.TypeString,
@@ -68,6 +85,32 @@ func resourceKibanaObject() *schema.Resource {
 				Optional: true,
 				Default:  true,
 			},
+			"delete_on_destroy": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"safe_delete": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"imported_objects": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -75,16 +118,16 @@ func resourceKibanaObject() *schema.Resource {
 // Import objects in Kibana
 func resourceKibanaObjectCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	name := d.Get("name").(string)
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_object", name, d.Get("space").(string))
 
-	err := importObject(d, meta)
+	err := importObject(ctx, d, meta)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId(name)
 
-	log.Infof("Imported objects %s successfully", name)
-	fmt.Printf("[INFO] Imported objects %s successfully", name)
+	tflog.Info(ctx, "Imported objects successfully", map[string]interface{}{"name": name})
 
 	return resourceKibanaObjectRead(ctx, d, meta)
 }
@@ -99,12 +142,20 @@ func resourceKibanaObjectRead(ctx context.Context, d *schema.ResourceData, meta
 	deepReference := d.Get("deep_reference").(bool)
 	space := d.Get("space").(string)
 
-	log.Debugf("Object id:  %s", id)
-	log.Debugf("Export types: %+v", exportTypes)
-	log.Debugf("Export Objects: %+v", exportObjects)
-	log.Debugf("Space: %s", space)
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_object", d.Id(), space)
+	client := meta.(*providerMeta).clientWithContext(ctx)
 
-	client := meta.(*kibana.Client)
+	tflog.Debug(ctx, "Export types", map[string]interface{}{"export_types": exportTypes})
+	tflog.Debug(ctx, "Export objects", map[string]interface{}{"export_objects": exportObjects})
+
+	// The imported objects come from data, or from the objects on import ID
+	// When state come from old provider, they are computed from the data on state
+	importedObjects := buildExportObjects(d.Get("imported_objects").(*schema.Set).List())
+	if len(importedObjects) == 0 {
+		if importedObjects, err = parseNDJSONObjects(d.Get("data").(string)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
 	data, err := client.API.KibanaSavedObject.Export(exportTypes, exportObjects, deepReference, space)
 	if err != nil {
@@ -112,13 +163,12 @@ func resourceKibanaObjectRead(ctx context.Context, d *schema.ResourceData, meta
 	}
 
 	if len(data) == 0 {
-		log.Warnf("Export object %s not found - removing from state", id)
-		fmt.Printf("[WARN] Export object %s not found - removing from state", id)
+		tflog.Warn(ctx, "Export object not found - removing from state", map[string]interface{}{"id": id})
 		d.SetId("")
 		return nil
 	}
 
-	log.Debugf("Export object %s successfully:\n%+v", id, string(data))
+	tflog.Debug(ctx, "Export object successfully", map[string]interface{}{"id": id, "data": string(data)})
 
 	if err = d.Set("name", id); err != nil {
 		return diag.FromErr(err)
@@ -136,8 +186,20 @@ func resourceKibanaObjectRead(ctx context.Context, d *schema.ResourceData, meta
 		return diag.FromErr(err)
 	}
 
-	log.Infof("Export object %s successfully", id)
-	fmt.Printf("[INFO] Export object %s successfully", id)
+	// Keep only the imported objects that still exist
+	exportedObjects, err := parseNDJSONObjects(string(data))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	importedObjects, err = refreshImportedObjects(client, importedObjects, exportedObjects, space)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("imported_objects", importedObjects); err != nil {
+		return diag.FromErr(err)
+	}
+
+	tflog.Info(ctx, "Export object successfully", map[string]interface{}{"id": id})
 
 	return nil
 }
@@ -145,30 +207,88 @@ func resourceKibanaObjectRead(ctx context.Context, d *schema.ResourceData, meta
 // Update existing object in Kibana
 func resourceKibanaObjectUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_object", id, d.Get("space").(string))
 
-	err := importObject(d, meta)
+	err := importObject(ctx, d, meta)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	log.Infof("Updated object %s successfully", id)
-	fmt.Printf("[INFO] Updated object %s successfully", id)
+	tflog.Info(ctx, "Updated object successfully", map[string]interface{}{"id": id})
 
 	return resourceKibanaObjectRead(ctx, d, meta)
 }
 
-// Delete object in Kibana is not supported
-// It just remove object from state
+// Delete objects in Kibana
+// By default, it just remove object from state
 func resourceKibanaObjectDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	id := d.Id()
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_object", id, d.Get("space").(string))
+
+	if !d.Get("delete_on_destroy").(bool) {
+		d.SetId("")
+
+		tflog.Info(ctx, "Delete object is disabled - just removing from state", map[string]interface{}{"id": id})
+		return nil
+	}
+
+	err := deleteObject(ctx, d, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	d.SetId("")
 
-	log.Infof("Delete object in not supported - just removing from state")
-	fmt.Printf("[INFO] Delete object in not supported - just removing from state")
+	tflog.Info(ctx, "Deleted objects successfully", map[string]interface{}{"id": id})
 	return nil
 
 }
 
+// Import existing objects from Kibana
+// The ID must be formated as <name>:<space>:<type>/<id>,<type>/<id>
+func resourceKibanaObjectImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), ":", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		return nil, errors.Errorf("ID %s must be formated as <name>:<space>:<type>/<id>,<type>/<id>", d.Id())
+	}
+	name := parts[0]
+	space := parts[1]
+	exportObjects, err := parseImportObjects(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_object", name, space)
+
+	d.SetId(name)
+	if err = d.Set("name", name); err != nil {
+		return nil, err
+	}
+	if err = d.Set("space", space); err != nil {
+		return nil, err
+	}
+	if err = d.Set("export_objects", exportObjects); err != nil {
+		return nil, err
+	}
+	if err = d.Set("deep_reference", true); err != nil {
+		return nil, err
+	}
+	if err = d.Set("delete_on_destroy", false); err != nil {
+		return nil, err
+	}
+	if err = d.Set("safe_delete", true); err != nil {
+		return nil, err
+	}
+
+	// Only the objects on import ID are deleted on destroy, not the objects pulled by deep reference
+	if err = d.Set("imported_objects", exportObjects); err != nil {
+		return nil, err
+	}
+
+	tflog.Info(ctx, "Imported objects from space successfully", map[string]interface{}{"name": name, "space": space})
+
+	return []*schema.ResourceData{d}, nil
+}
+
 // Build list of object to export
 func buildExportObjects(raws []interface{}) []map[string]string {
 
@@ -187,25 +307,196 @@ func buildExportObjects(raws []interface{}) []map[string]string {
 }
 
 // Import objects in Kibana
-func importObject(d *schema.ResourceData, meta interface{}) error {
+func importObject(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	data := d.Get("data").(string)
 	space := d.Get("space").(string)
 
-	log.Debugf("Data to import: %s", data)
+	tflog.Debug(ctx, "Data to import", map[string]interface{}{"data": data})
 
 	var (
 		importedData map[string]interface{}
 		err          error
 	)
 
-	client := meta.(*kibana.Client)
+	client := meta.(*providerMeta).clientWithContext(ctx)
 
 	importedData, err = client.API.KibanaSavedObject.Import([]byte(data), true, space)
 	if err != nil {
 		return err
 	}
 
-	log.Debugf("Imported object: %+v", importedData)
+	tflog.Debug(ctx, "Imported object", map[string]interface{}{"imported_data": importedData})
+
+	// Keep imported objects to be able to delete them later
+	importedObjects, err := parseNDJSONObjects(data)
+	if err != nil {
+		return err
+	}
+	if err = d.Set("imported_objects", importedObjects); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// refreshImportedObjects permit to remove the imported objects that not exist anymore
+// The objects not on exported objects are checked one by one, because export can be filtered by type
+func refreshImportedObjects(client *kibana.Client, importedObjects []map[string]string, exportedObjects []map[string]string, space string) ([]map[string]string, error) {
+	results := make([]map[string]string, 0, len(importedObjects))
+
+	for _, object := range importedObjects {
+		if !isObjectInList(object["type"], object["id"], exportedObjects) {
+			savedObject, err := client.API.KibanaSavedObject.Get(object["type"], object["id"], space)
+			if err != nil {
+				return nil, err
+			}
+			if savedObject == nil {
+				continue
+			}
+		}
+		results = append(results, object)
+	}
+
+	return results, nil
+}
+
+// Delete imported objects in Kibana
+func deleteObject(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
+	space := d.Get("space").(string)
+	safeDelete := d.Get("safe_delete").(bool)
+	objects := buildExportObjects(d.Get("imported_objects").(*schema.Set).List())
+
+	tflog.Debug(ctx, "Objects to delete", map[string]interface{}{"objects": objects})
+
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	if safeDelete {
+		objectTypes, err := getReferencingObjectTypes(d.Get("data").(string), objects)
+		if err != nil {
+			return err
+		}
+		if objects, err = getUnreferencedObjects(ctx, client, objects, objectTypes, space); err != nil {
+			return err
+		}
+	}
+
+	for _, object := range objects {
+		if err := client.API.KibanaSavedObject.Delete(object["type"], object["id"], space); err != nil {
+			if apiErr, ok := err.(kbapi.APIError); ok && apiErr.Code == 404 {
+				tflog.Warn(ctx, "Object not found - skip it", map[string]interface{}{"object_type": object["type"], "object_id": object["id"]})
+				continue
+			}
+			return err
+		}
+
+		tflog.Debug(ctx, "Deleted object successfully", map[string]interface{}{"object_type": object["type"], "object_id": object["id"]})
+	}
 
 	return nil
 }
+
+// getReferencingObjectTypes permit to get the object types to search references on
+// They are the types of exported objects and the types of objects we delete
+func getReferencingObjectTypes(data string, objects []map[string]string) ([]string, error) {
+	exportedObjects, err := parseNDJSONObjects(data)
+	if err != nil {
+		return nil, err
+	}
+
+	objectTypes := make([]string, 0, len(exportedObjects)+len(objects))
+	knownTypes := map[string]bool{}
+	for _, object := range append(exportedObjects, objects...) {
+		if !knownTypes[object["type"]] {
+			knownTypes[object["type"]] = true
+			objectTypes = append(objectTypes, object["type"])
+		}
+	}
+
+	return objectTypes, nil
+}
+
+// getUnreferencedObjects permit to get the objects that can be deleted
+// An object referenced by a kept object is kept too, so we iterate until the kept objects not change
+func getUnreferencedObjects(ctx context.Context, client *kibana.Client, objects []map[string]string, objectTypes []string, space string) ([]map[string]string, error) {
+	deletedObjects := objects
+
+	for {
+		results := make([]map[string]string, 0, len(deletedObjects))
+		for _, object := range deletedObjects {
+			isReferenced, err := isObjectReferenced(ctx, client, object, deletedObjects, objectTypes, space)
+			if err != nil {
+				return nil, err
+			}
+			if isReferenced {
+				tflog.Warn(ctx, "Object is referenced by other objects - skip it", map[string]interface{}{"object_type": object["type"], "object_id": object["id"]})
+				continue
+			}
+			results = append(results, object)
+		}
+
+		if len(results) == len(deletedObjects) {
+			return results, nil
+		}
+		deletedObjects = results
+	}
+}
+
+// isObjectReferenced permit to check if object is referenced by another objects than the objects we delete
+func isObjectReferenced(ctx context.Context, client *kibana.Client, object map[string]string, deletedObjects []map[string]string, objectTypes []string, space string) (bool, error) {
+
+	hasReference, err := json.Marshal(object)
+	if err != nil {
+		return false, err
+	}
+
+	// Find API need the object type, so we search on each type that can reference an object
+	for _, objectType := range objectTypes {
+		res, err := client.API.KibanaSavedObject.Find(objectType, space, &kbapi.OptionalFindParameters{
+			HasReference:   string(hasReference),
+			ObjectsPerPage: 100,
+		})
+		if err != nil {
+			// Kibana return bad request when type is not registered
+			if apiErr, ok := err.(kbapi.APIError); ok && apiErr.Code == 400 {
+				tflog.Debug(ctx, "Object type not supported - skip it", map[string]interface{}{"object_type": objectType})
+				continue
+			}
+			return false, err
+		}
+		if res == nil {
+			continue
+		}
+
+		savedObjects, _ := res["saved_objects"].([]interface{})
+		if total, ok := res["total"].(float64); ok && int(total) > len(savedObjects) {
+			return true, nil
+		}
+		for _, savedObject := range savedObjects {
+			m, ok := savedObject.(map[string]interface{})
+			if !ok {
+				return false, errors.Errorf("Unexpected saved object %v when search references of %s/%s", savedObject, object["type"], object["id"])
+			}
+			savedObjectType, hasType := m["type"].(string)
+			savedObjectID, hasID := m["id"].(string)
+			if !hasType || !hasID {
+				return false, errors.Errorf("Saved object %v has no type or id when search references of %s/%s", m, object["type"], object["id"])
+			}
+			if !isObjectInList(savedObjectType, savedObjectID, deletedObjects) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// isObjectInList permit to check if object is on list of objects
+func isObjectInList(objectType string, id string, objects []map[string]string) bool {
+	for _, object := range objects {
+		if object["type"] == objectType && object["id"] == id {
+			return true
+		}
+	}
+
+	return false
+}
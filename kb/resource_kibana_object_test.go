@@ -1,12 +1,16 @@
 package kb
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 
-	kibana "github.com/disaster37/go-kibana-rest/v8"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/pkg/errors"
 )
@@ -31,6 +35,188 @@ func TestAccKibanaObject(t *testing.T) {
 	})
 }
 
+func TestAccKibanaObjectDeleteOnDestroy(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckKibanaObjectDeleted,
+		Steps: []resource.TestStep{
+			{
+				Config: testKibanaObjectDeleteOnDestroy,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("kibana_object.test_delete", "imported_objects.#", "1"),
+				),
+			},
+			{
+				ResourceName:            "kibana_object.test_delete",
+				ImportState:             true,
+				ImportStateId:           "terraform-test-delete:default:index-pattern/terraform-test-delete",
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"delete_on_destroy"},
+			},
+		},
+	})
+}
+
+func TestParseNDJSONObjects(t *testing.T) {
+	data := `{"id":"test","type":"index-pattern","attributes":{"title":"test"}}
+
+{"id":"dashboard1","type":"dashboard","attributes":{"title":"dashboard"}}
+{"exportedCount":2,"missingRefCount":0,"missingReferences":[]}`
+
+	objects, err := parseNDJSONObjects(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("Expected 2 objects, got %d", len(objects))
+	}
+	if objects[0]["id"] != "test" || objects[0]["type"] != "index-pattern" {
+		t.Errorf("Unexpected first object: %+v", objects[0])
+	}
+	if objects[1]["id"] != "dashboard1" || objects[1]["type"] != "dashboard" {
+		t.Errorf("Unexpected second object: %+v", objects[1])
+	}
+
+	if _, err = parseNDJSONObjects("not json"); err == nil {
+		t.Error("Expected error on bad NDJSON")
+	}
+}
+
+func TestRefreshImportedObjects(t *testing.T) {
+	meta := newTestProviderMeta(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/saved_objects/search/search1" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"search1","type":"search","attributes":{"title":"search"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	// The object filtered from export is kept when it still exists, and the deleted object is removed
+	objects, err := refreshImportedObjects(
+		meta.client,
+		[]map[string]string{{"type": "dashboard", "id": "dashboard1"}, {"type": "search", "id": "search1"}, {"type": "search", "id": "deleted"}},
+		[]map[string]string{{"type": "dashboard", "id": "dashboard1"}, {"type": "index-pattern", "id": "test"}},
+		"default",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []map[string]string{{"type": "dashboard", "id": "dashboard1"}, {"type": "search", "id": "search1"}}
+	if !reflect.DeepEqual(objects, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, objects)
+	}
+}
+
+func TestIsObjectReferenced(t *testing.T) {
+	object := map[string]string{"type": "index-pattern", "id": "test"}
+	deletedObjects := []map[string]string{object, {"type": "dashboard", "id": "dashboard1"}}
+
+	// Only referenced by deleted objects
+	meta := newTestProviderMeta(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"total": 1, "saved_objects": [{"type": "dashboard", "id": "dashboard1"}]}`))
+	})
+	isReferenced, err := isObjectReferenced(context.Background(), meta.client, object, deletedObjects, []string{"dashboard"}, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isReferenced {
+		t.Errorf("Object referenced only by deleted objects must be deleted")
+	}
+
+	// Malformed response
+	meta = newTestProviderMeta(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"total": 1, "saved_objects": [{"id": "dashboard1"}]}`))
+	})
+	if _, err = isObjectReferenced(context.Background(), meta.client, object, deletedObjects, []string{"dashboard"}, "default"); err == nil {
+		t.Errorf("Saved object without type must return error")
+	}
+}
+
+func TestGetUnreferencedObjects(t *testing.T) {
+	meta := newTestProviderMeta(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Query().Get("type") == "search" && strings.Contains(r.URL.Query().Get("has_reference"), `"test"`):
+			_, _ = w.Write([]byte(`{"total": 1, "saved_objects": [{"type": "search", "id": "search1"}]}`))
+		case r.URL.Query().Get("type") == "dashboard" && strings.Contains(r.URL.Query().Get("has_reference"), `"search1"`):
+			_, _ = w.Write([]byte(`{"total": 1, "saved_objects": [{"type": "dashboard", "id": "other"}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"total": 0, "saved_objects": []}`))
+		}
+	})
+
+	// The search is referenced by another dashboard, so the index pattern used by the kept search is kept too
+	objects := []map[string]string{{"type": "index-pattern", "id": "test"}, {"type": "search", "id": "search1"}, {"type": "visualization", "id": "visualization1"}}
+	objects, err := getUnreferencedObjects(context.Background(), meta.client, objects, []string{"dashboard", "search", "index-pattern", "visualization"}, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []map[string]string{{"type": "visualization", "id": "visualization1"}}
+	if !reflect.DeepEqual(objects, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, objects)
+	}
+}
+
+func TestGetReferencingObjectTypes(t *testing.T) {
+	data := `{"id":"dashboard1","type":"dashboard","attributes":{"title":"dashboard"}}
+{"id":"test","type":"index-pattern","attributes":{"title":"test"}}`
+
+	objectTypes, err := getReferencingObjectTypes(data, []map[string]string{{"type": "index-pattern", "id": "test"}, {"type": "search", "id": "search1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"dashboard", "index-pattern", "search"}
+	if !reflect.DeepEqual(objectTypes, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, objectTypes)
+	}
+}
+
+func TestResourceKibanaObjectImport(t *testing.T) {
+	meta := newTestProviderMeta(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected call %s %s", r.Method, r.URL.Path)
+	})
+
+	d := schema.TestResourceDataRaw(t, resourceKibanaObject().Schema, map[string]interface{}{})
+	d.SetId("test:default:dashboard/dashboard1")
+	if _, err := resourceKibanaObjectImport(context.Background(), d, meta); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the objects on import ID are kept to be deleted on destroy
+	objects := buildExportObjects(d.Get("imported_objects").(*schema.Set).List())
+	expected := []map[string]string{{"type": "dashboard", "id": "dashboard1"}}
+	if !reflect.DeepEqual(objects, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, objects)
+	}
+}
+
+func TestParseImportObjects(t *testing.T) {
+	objects, err := parseImportObjects("index-pattern/logstash-*,dashboard/my/dashboard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("Expected 2 objects, got %d", len(objects))
+	}
+	if objects[0]["type"] != "index-pattern" || objects[0]["id"] != "logstash-*" {
+		t.Errorf("Unexpected first object: %+v", objects[0])
+	}
+	if objects[1]["type"] != "dashboard" || objects[1]["id"] != "my/dashboard" {
+		t.Errorf("Unexpected second object: %+v", objects[1])
+	}
+
+	if _, err = parseImportObjects("index-pattern"); err == nil {
+		t.Error("Expected error when object has no id")
+	}
+}
+
 func testCheckKibanaObjectExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -51,7 +237,7 @@ func testCheckKibanaObjectExists(name string) resource.TestCheckFunc {
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*kibana.Client)
+		client := meta.(*providerMeta).client
 		data, err := client.API.KibanaSavedObject.Export(nil, exportObjects, deepReference, space)
 		if err != nil {
 			return err
@@ -75,6 +261,39 @@ func testCheckKibanaObjectDestroy(s *terraform.State) error {
 	return nil
 }
 
+func testCheckKibanaObjectDeleted(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "kibana_object" {
+			continue
+		}
+
+		meta := testAccProvider.Meta()
+
+		client := meta.(*providerMeta).client
+		object, err := client.API.KibanaSavedObject.Get("index-pattern", "terraform-test-delete", "default")
+		if err != nil {
+			return err
+		}
+		if object != nil {
+			return fmt.Errorf("Object %q still exists", rs.Primary.ID)
+		}
+	}
+
+	return nil
+}
+
+var testKibanaObjectDeleteOnDestroy = `
+resource "kibana_object" "test_delete" {
+  name 				= "terraform-test-delete"
+  data				= "{\"id\": \"terraform-test-delete\", \"type\": \"index-pattern\",\"attributes\": {\"title\": \"terraform-test-delete\"}}"
+  export_objects {
+	  id   = "terraform-test-delete"
+	  type = "index-pattern"
+  }
+  delete_on_destroy = true
+}
+`
+
 func getTestKibanaObject() string {
 	path, err := os.Getwd()
 	if err != nil {
@@ -9,12 +9,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
-	kibana "github.com/disaster37/go-kibana-rest/v8"
 	kbapi "github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	log "github.com/sirupsen/logrus"
+	"github.com/pkg/errors"
 )
 
 // Resource specification to handle role in Kibana
@@ -25,10 +28,39 @@ func resourceKibanaRole() *schema.Resource {
 		UpdateContext: resourceKibanaRoleUpdate,
 		DeleteContext: resourceKibanaRoleDelete,
 
+		CustomizeDiff: customizeDiffMinimalVersion(
+			versionRequirement{
+				attribute: "elasticsearch.remote_indices",
+				version:   "8.10.0",
+				isUsed:    isAttributeSet("elasticsearch.0.remote_indices"),
+			},
+			versionRequirement{
+				attribute: "elasticsearch.remote_cluster",
+				version:   "8.15.0",
+				isUsed:    isAttributeSet("elasticsearch.0.remote_cluster"),
+			},
+		),
+
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(30 * time.Second),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Type:    resourceKibanaRoleV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceKibanaRoleStateUpgradeV0,
+				Version: 0,
+			},
+		},
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -36,8 +68,9 @@ func resourceKibanaRole() *schema.Resource {
 				ForceNew: true,
 			},
 			"elasticsearch": {
-				Type:     schema.TypeSet,
+				Type:     schema.TypeList,
 				Optional: true,
+				MaxItems: 1,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"indices": {
@@ -53,12 +86,81 @@ func resourceKibanaRole() *schema.Resource {
 										},
 									},
 									"privileges": {
+										Type:     schema.TypeSet,
+										Required: true,
+										Elem: &schema.Schema{
+											Type:             schema.TypeString,
+											ValidateDiagFunc: validatePrivilege(indexPrivileges),
+										},
+									},
+									"query": {
+										Type:             schema.TypeString,
+										Optional:         true,
+										DiffSuppressFunc: suppressEquivalentJSON,
+									},
+									"field_security": {
+										Type:             schema.TypeString,
+										Optional:         true,
+										DiffSuppressFunc: suppressEquivalentJSON,
+									},
+									"field_level_security": {
+										Type:     schema.TypeList,
+										Optional: true,
+										MaxItems: 1,
+										Elem: &schema.Resource{
+											Schema: map[string]*schema.Schema{
+												"grant": {
+													Type:     schema.TypeSet,
+													Optional: true,
+													Elem: &schema.Schema{
+														Type: schema.TypeString,
+													},
+												},
+												"except": {
+													Type:     schema.TypeSet,
+													Optional: true,
+													Elem: &schema.Schema{
+														Type: schema.TypeString,
+													},
+												},
+											},
+										},
+									},
+									"allow_restricted_indices": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  false,
+									},
+								},
+							},
+						},
+						"remote_indices": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"clusters": {
 										Type:     schema.TypeSet,
 										Required: true,
 										Elem: &schema.Schema{
 											Type: schema.TypeString,
 										},
 									},
+									"names": {
+										Type:     schema.TypeSet,
+										Required: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+									"privileges": {
+										Type:     schema.TypeSet,
+										Required: true,
+										Elem: &schema.Schema{
+											Type:             schema.TypeString,
+											ValidateDiagFunc: validatePrivilege(indexPrivileges),
+										},
+									},
 									"query": {
 										Type:             schema.TypeString,
 										Optional:         true,
@@ -69,6 +171,34 @@ func resourceKibanaRole() *schema.Resource {
 										Optional:         true,
 										DiffSuppressFunc: suppressEquivalentJSON,
 									},
+									"field_level_security": {
+										Type:     schema.TypeList,
+										Optional: true,
+										MaxItems: 1,
+										Elem: &schema.Resource{
+											Schema: map[string]*schema.Schema{
+												"grant": {
+													Type:     schema.TypeSet,
+													Optional: true,
+													Elem: &schema.Schema{
+														Type: schema.TypeString,
+													},
+												},
+												"except": {
+													Type:     schema.TypeSet,
+													Optional: true,
+													Elem: &schema.Schema{
+														Type: schema.TypeString,
+													},
+												},
+											},
+										},
+									},
+									"allow_restricted_indices": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  false,
+									},
 								},
 							},
 						},
@@ -76,7 +206,31 @@ func resourceKibanaRole() *schema.Resource {
 							Type:     schema.TypeSet,
 							Optional: true,
 							Elem: &schema.Schema{
-								Type: schema.TypeString,
+								Type:             schema.TypeString,
+								ValidateDiagFunc: validatePrivilege(clusterPrivileges),
+							},
+						},
+						"remote_cluster": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"clusters": {
+										Type:     schema.TypeSet,
+										Required: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+									"privileges": {
+										Type:     schema.TypeSet,
+										Required: true,
+										Elem: &schema.Schema{
+											Type:             schema.TypeString,
+											ValidateDiagFunc: validatePrivilege(remoteClusterPrivileges),
+										},
+									},
+								},
 							},
 						},
 						"run_as": {
@@ -92,7 +246,6 @@ func resourceKibanaRole() *schema.Resource {
 			"kibana": {
 				Type:     schema.TypeSet,
 				Optional: true,
-				MaxItems: 1,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"base": {
@@ -144,16 +297,16 @@ func resourceKibanaRole() *schema.Resource {
 func resourceKibanaRoleCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 
 	name := d.Get("name").(string)
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_role", name, "")
 
-	err := createRole(d, meta)
+	err := createRole(ctx, d, meta)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId(name)
 
-	log.Infof("Created role %s successfully", name)
-	fmt.Printf("[INFO] Created role %s successfully", name)
+	tflog.Info(ctx, "Created role successfully", map[string]interface{}{"name": name})
 
 	return resourceKibanaRoleRead(ctx, d, meta)
 }
@@ -164,34 +317,34 @@ func resourceKibanaRoleRead(ctx context.Context, d *schema.ResourceData, meta in
 	var err error
 	id := d.Id()
 
-	log.Debugf("Role id:  %s", id)
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_role", d.Id(), "")
+	client := meta.(*providerMeta).clientWithContext(ctx)
 
-	client := meta.(*kibana.Client)
-
-	role, err := client.API.KibanaRoleManagement.Get(id)
+	role, err := getKibanaRole(ctx, client.Client, id)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	if role == nil {
-		log.Warnf("Role %s not found - removing from state", id)
-		fmt.Printf("[WARN] Role %s not found - removing from state", id)
+		tflog.Warn(ctx, "Role not found - removing from state", map[string]interface{}{"id": id})
 		d.SetId("")
 		return nil
 	}
 
-	log.Debugf("Get role %s successfully:\n%s", id, role)
+	tflog.Debug(ctx, "Get role successfully", map[string]interface{}{"id": id, "role": role.String()})
 
 	if err = d.Set("name", id); err != nil {
 		return diag.FromErr(err)
 	}
 
-	flattenKRE, err := flattenKibanaRoleElasticsearchMappings(role.Elasticsearch)
+	// Keep field security as JSON string for indices that already use it
+	jsonFieldSecurityIndices := getIndicesWithJSONFieldSecurity(d.Get("elasticsearch").([]interface{}))
+	flattenKRE, err := flattenKibanaRoleElasticsearchMappings(role.Elasticsearch, jsonFieldSecurityIndices)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	log.Debugf("Flatten ES: +%v\n", flattenKRE)
+	tflog.Debug(ctx, "Flatten ES", map[string]interface{}{"elasticsearch": flattenKRE})
 	if err = d.Set("elasticsearch", flattenKRE); err != nil {
 		return diag.FromErr(fmt.Errorf("error setting elasticsearch: %w", err))
 	}
@@ -208,8 +361,7 @@ func resourceKibanaRoleRead(ctx context.Context, d *schema.ResourceData, meta in
 		return diag.FromErr(fmt.Errorf("error setting metadata: %w", err))
 	}
 
-	log.Infof("Read role %s successfully", id)
-	fmt.Printf("[INFO] Read role %s successfully", id)
+	tflog.Info(ctx, "Read role successfully", map[string]interface{}{"id": id})
 
 	return nil
 }
@@ -217,14 +369,14 @@ func resourceKibanaRoleRead(ctx context.Context, d *schema.ResourceData, meta in
 // Update existing role in Elasticsearch
 func resourceKibanaRoleUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	id := d.Id()
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_role", id, "")
 
-	err := createRole(d, meta)
+	err := createRole(ctx, d, meta)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	log.Infof("Updated role %s successfully", id)
-	fmt.Printf("[INFO] Updated role %s successfully", id)
+	tflog.Info(ctx, "Updated role successfully", map[string]interface{}{"id": id})
 
 	return resourceKibanaRoleRead(ctx, d, meta)
 }
@@ -233,15 +385,14 @@ func resourceKibanaRoleUpdate(ctx context.Context, d *schema.ResourceData, meta
 func resourceKibanaRoleDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 
 	id := d.Id()
-	log.Debugf("Role id: %s", id)
 
-	client := meta.(*kibana.Client)
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_role", d.Id(), "")
+	client := meta.(*providerMeta).clientWithContext(ctx)
 
 	err := client.API.KibanaRoleManagement.Delete(id)
 	if err != nil {
 		if err.(kbapi.APIError).Code == 404 {
-			log.Warnf("Role %s not found - removing from state", id)
-			fmt.Printf("[WARN] Role %s not found - removing from state", id)
+			tflog.Warn(ctx, "Role not found - removing from state", map[string]interface{}{"id": id})
 			d.SetId("")
 			return nil
 		}
@@ -251,23 +402,22 @@ func resourceKibanaRoleDelete(ctx context.Context, d *schema.ResourceData, meta
 
 	d.SetId("")
 
-	log.Infof("Deleted role %s successfully", id)
-	fmt.Printf("[INFO] Deleted role %s successfully", id)
+	tflog.Info(ctx, "Deleted role successfully", map[string]interface{}{"id": id})
 	return nil
 
 }
 
 // createRole permit to create or update role in Kibana
-func createRole(d *schema.ResourceData, meta interface{}) error {
+func createRole(ctx context.Context, d *schema.ResourceData, meta interface{}) error {
 	name := d.Get("name").(string)
 	metadataTemp := optionalInterfaceJSON(d.Get("metadata").(string))
-	roleElasticsearch, err := buildRolesElasticsearch(d.Get("elasticsearch").(*schema.Set).List())
+	roleElasticsearch, err := buildRolesElasticsearch(d.Get("elasticsearch").([]interface{}))
 	if err != nil {
 		return err
 	}
 	roleKibana := buildRolesKibana(d.Get("kibana").(*schema.Set).List())
 
-	client := meta.(*kibana.Client)
+	client := meta.(*providerMeta).clientWithContext(ctx)
 
 	var metadata map[string]interface{}
 	if metadataTemp != nil {
@@ -278,14 +428,14 @@ func createRole(d *schema.ResourceData, meta interface{}) error {
 	} else {
 		metadata = nil
 	}
-	role := &kbapi.KibanaRole{
+	role := &kibanaRole{
 		Name:          name,
 		Elasticsearch: roleElasticsearch,
 		Kibana:        roleKibana,
 		Metadata:      metadata,
 	}
 
-	_, err = client.API.KibanaRoleManagement.CreateOrUpdate(role)
+	err = createOrUpdateKibanaRole(ctx, client.Client, role)
 	if err != nil {
 		return err
 	}
@@ -294,15 +444,15 @@ func createRole(d *schema.ResourceData, meta interface{}) error {
 }
 
 // buildRolesElasticsearch permit to construct kibanaRoleElasticsearch object
-func buildRolesElasticsearch(raws []interface{}) (*kbapi.KibanaRoleElasticsearch, error) {
-	if len(raws) == 0 {
+func buildRolesElasticsearch(raws []interface{}) (*kibanaRoleElasticsearch, error) {
+	// Schema allow only one elasticsearch block, it can be empty
+	if len(raws) == 0 || raws[0] == nil {
 		return nil, nil
 	}
 
-	// We check only the first, we case use multiple KibanaRoleElasticsearch
 	raw := raws[0].(map[string]interface{})
 
-	kibanaRoleElasticsearch := &kbapi.KibanaRoleElasticsearch{}
+	kibanaRoleElasticsearch := &kibanaRoleElasticsearch{}
 
 	if _, ok := raw["run_as"]; ok {
 		kibanaRoleElasticsearch.RunAs = convertArrayInterfaceToArrayString(raw["run_as"].(*schema.Set).List())
@@ -310,6 +460,9 @@ func buildRolesElasticsearch(raws []interface{}) (*kbapi.KibanaRoleElasticsearch
 	if _, ok := raw["cluster"]; ok {
 		kibanaRoleElasticsearch.Cluster = convertArrayInterfaceToArrayString(raw["cluster"].(*schema.Set).List())
 	}
+	if _, ok := raw["remote_cluster"]; ok {
+		kibanaRoleElasticsearch.RemoteCluster = buildKibanaRoleElasticsearchRemoteCluster(raw["remote_cluster"].(*schema.Set).List())
+	}
 	if _, ok := raw["indices"]; ok {
 		krei, err := buildKibanaRoleElasticsearchIndice(raw["indices"].(*schema.Set).List())
 		if err != nil {
@@ -317,38 +470,112 @@ func buildRolesElasticsearch(raws []interface{}) (*kbapi.KibanaRoleElasticsearch
 		}
 		kibanaRoleElasticsearch.Indices = krei
 	}
+	if _, ok := raw["remote_indices"]; ok {
+		krei, err := buildKibanaRoleElasticsearchRemoteIndice(raw["remote_indices"].(*schema.Set).List())
+		if err != nil {
+			return nil, err
+		}
+		kibanaRoleElasticsearch.RemoteIndices = krei
+	}
 
 	return kibanaRoleElasticsearch, nil
 
 }
 
-// buildKibanaRoleElasticsearchIndice permit to build list of KibanaRoleElasticsearchIndice
-func buildKibanaRoleElasticsearchIndice(raws []interface{}) ([]kbapi.KibanaRoleElasticsearchIndice, error) {
-	kibanaRoleElasticsearchIndices := make([]kbapi.KibanaRoleElasticsearchIndice, len(raws))
+// buildKibanaRoleElasticsearchIndice permit to build list of kibanaRoleElasticsearchIndice
+func buildKibanaRoleElasticsearchIndice(raws []interface{}) ([]kibanaRoleElasticsearchIndice, error) {
+	kibanaRoleElasticsearchIndices := make([]kibanaRoleElasticsearchIndice, len(raws))
+	for i, raw := range raws {
+		kibanaRoleElasticsearchIndice, err := buildKibanaRoleElasticsearchIndiceItem(raw.(map[string]interface{}))
+		if err != nil {
+			return nil, err
+		}
+
+		kibanaRoleElasticsearchIndices[i] = kibanaRoleElasticsearchIndice
+	}
+
+	return kibanaRoleElasticsearchIndices, nil
+}
+
+// buildKibanaRoleElasticsearchRemoteIndice permit to build list of kibanaRoleElasticsearchRemoteIndice
+func buildKibanaRoleElasticsearchRemoteIndice(raws []interface{}) ([]kibanaRoleElasticsearchRemoteIndice, error) {
+	kibanaRoleElasticsearchRemoteIndices := make([]kibanaRoleElasticsearchRemoteIndice, len(raws))
 	for i, raw := range raws {
 		m := raw.(map[string]interface{})
-		fieldSecurityTemp := optionalInterfaceJSON(m["field_security"].(string))
-		var fieldSecurity map[string]interface{}
-		if fieldSecurityTemp != nil {
+		kibanaRoleElasticsearchIndice, err := buildKibanaRoleElasticsearchIndiceItem(m)
+		if err != nil {
+			return nil, err
+		}
 
-			if err := json.Unmarshal(fieldSecurityTemp.(json.RawMessage), &fieldSecurity); err != nil {
-				return nil, err
-			}
+		kibanaRoleElasticsearchRemoteIndices[i] = kibanaRoleElasticsearchRemoteIndice{
+			kibanaRoleElasticsearchIndice: kibanaRoleElasticsearchIndice,
+			Clusters:                      convertArrayInterfaceToArrayString(m["clusters"].(*schema.Set).List()),
+		}
+	}
+
+	return kibanaRoleElasticsearchRemoteIndices, nil
+}
+
+// buildKibanaRoleElasticsearchIndiceItem permit to build kibanaRoleElasticsearchIndice
+func buildKibanaRoleElasticsearchIndiceItem(m map[string]interface{}) (kibanaRoleElasticsearchIndice, error) {
+	fieldSecurityTemp := optionalInterfaceJSON(m["field_security"].(string))
+	fieldLevelSecurity := m["field_level_security"].([]interface{})
+	var fieldSecurity map[string]interface{}
+	if fieldSecurityTemp != nil {
+		if len(fieldLevelSecurity) > 0 {
+			return kibanaRoleElasticsearchIndice{}, errors.New("field_security and field_level_security can't be set at the same time")
+		}
 
-		} else {
-			fieldSecurity = nil
+		if err := json.Unmarshal(fieldSecurityTemp.(json.RawMessage), &fieldSecurity); err != nil {
+			return kibanaRoleElasticsearchIndice{}, err
 		}
-		kibanaRoleElasticsearchIndice := kbapi.KibanaRoleElasticsearchIndice{
+
+	} else {
+		fieldSecurity = buildKibanaRoleFieldSecurity(fieldLevelSecurity)
+	}
+
+	return kibanaRoleElasticsearchIndice{
+		KibanaRoleElasticsearchIndice: kbapi.KibanaRoleElasticsearchIndice{
 			Names:         convertArrayInterfaceToArrayString(m["names"].(*schema.Set).List()),
 			Privileges:    convertArrayInterfaceToArrayString(m["privileges"].(*schema.Set).List()),
 			Query:         m["query"].(string),
 			FieldSecurity: fieldSecurity,
-		}
+		},
+		AllowRestrictedIndices: m["allow_restricted_indices"].(bool),
+	}, nil
+}
 
-		kibanaRoleElasticsearchIndices[i] = kibanaRoleElasticsearchIndice
+// buildKibanaRoleFieldSecurity permit to build field security from field_level_security block
+func buildKibanaRoleFieldSecurity(raws []interface{}) map[string]interface{} {
+	// Block can be empty
+	if len(raws) == 0 || raws[0] == nil {
+		return nil
 	}
 
-	return kibanaRoleElasticsearchIndices, nil
+	m := raws[0].(map[string]interface{})
+	fieldSecurity := map[string]interface{}{}
+	if grant := convertArrayInterfaceToArrayString(m["grant"].(*schema.Set).List()); len(grant) > 0 {
+		fieldSecurity["grant"] = grant
+	}
+	if except := convertArrayInterfaceToArrayString(m["except"].(*schema.Set).List()); len(except) > 0 {
+		fieldSecurity["except"] = except
+	}
+
+	return fieldSecurity
+}
+
+// buildKibanaRoleElasticsearchRemoteCluster permit to build list of kibanaRoleElasticsearchRemoteCluster
+func buildKibanaRoleElasticsearchRemoteCluster(raws []interface{}) []kibanaRoleElasticsearchRemoteCluster {
+	kibanaRoleElasticsearchRemoteClusters := make([]kibanaRoleElasticsearchRemoteCluster, len(raws))
+	for i, raw := range raws {
+		m := raw.(map[string]interface{})
+		kibanaRoleElasticsearchRemoteClusters[i] = kibanaRoleElasticsearchRemoteCluster{
+			Clusters:   convertArrayInterfaceToArrayString(m["clusters"].(*schema.Set).List()),
+			Privileges: convertArrayInterfaceToArrayString(m["privileges"].(*schema.Set).List()),
+		}
+	}
+
+	return kibanaRoleElasticsearchRemoteClusters
 }
 
 // buildRolesKibana permit to  build list of KibanaRoleKibana object
@@ -382,15 +609,15 @@ func buildKibanaRoleKibanaFeatures(raws []interface{}) map[string][]string {
 	return features
 }
 
-func flattenKibanaRoleElasticsearchMappings(kre *kbapi.KibanaRoleElasticsearch) ([]interface{}, error) {
+func flattenKibanaRoleElasticsearchMappings(kre *kibanaRoleElasticsearch, jsonFieldSecurityIndices map[string]bool) ([]interface{}, error) {
 
 	// Handle empty object
-	if kre == nil || (len(kre.Cluster) == 0 && len(kre.Indices) == 0 && len(kre.RunAs) == 0) {
+	if kre == nil || (len(kre.Cluster) == 0 && len(kre.Indices) == 0 && len(kre.RunAs) == 0 && len(kre.RemoteIndices) == 0 && len(kre.RemoteCluster) == 0) {
 		return nil, nil
 	}
 
 	var tfList []interface{}
-	flattenKRE, err := flattenKibanaRoleElasticsearchMapping(kre)
+	flattenKRE, err := flattenKibanaRoleElasticsearchMapping(kre, jsonFieldSecurityIndices)
 	if err != nil {
 		return nil, err
 	}
@@ -399,7 +626,7 @@ func flattenKibanaRoleElasticsearchMappings(kre *kbapi.KibanaRoleElasticsearch)
 	return tfList, nil
 }
 
-func flattenKibanaRoleElasticsearchMapping(kre *kbapi.KibanaRoleElasticsearch) (map[string]interface{}, error) {
+func flattenKibanaRoleElasticsearchMapping(kre *kibanaRoleElasticsearch, jsonFieldSecurityIndices map[string]bool) (map[string]interface{}, error) {
 	if kre == nil {
 		return nil, nil
 	}
@@ -407,19 +634,31 @@ func flattenKibanaRoleElasticsearchMapping(kre *kbapi.KibanaRoleElasticsearch) (
 	tfMap := make(map[string]interface{})
 
 	if kre.Indices != nil {
-		flatten, err := flattenKibanaRoleElasticsearchMappingsIndices(kre.Indices)
+		flatten, err := flattenKibanaRoleElasticsearchMappingsIndices(kre.Indices, jsonFieldSecurityIndices)
 		if err != nil {
 			return nil, err
 		}
 		tfMap["indices"] = flatten
 	}
 
+	if kre.RemoteIndices != nil {
+		flatten, err := flattenKibanaRoleElasticsearchMappingsRemoteIndices(kre.RemoteIndices, jsonFieldSecurityIndices)
+		if err != nil {
+			return nil, err
+		}
+		tfMap["remote_indices"] = flatten
+	}
+
 	if kre.Cluster != nil {
 		tfMap["cluster"] = kre.Cluster
 	} else {
 		tfMap["cluster"] = make([]interface{}, 0)
 	}
 
+	if kre.RemoteCluster != nil {
+		tfMap["remote_cluster"] = flattenKibanaRoleElasticsearchMappingsRemoteCluster(kre.RemoteCluster)
+	}
+
 	if kre.RunAs != nil {
 		tfMap["run_as"] = kre.RunAs
 	} else {
@@ -429,7 +668,7 @@ func flattenKibanaRoleElasticsearchMapping(kre *kbapi.KibanaRoleElasticsearch) (
 	return tfMap, nil
 }
 
-func flattenKibanaRoleElasticsearchMappingsIndices(krei []kbapi.KibanaRoleElasticsearchIndice) ([]interface{}, error) {
+func flattenKibanaRoleElasticsearchMappingsIndices(krei []kibanaRoleElasticsearchIndice, jsonFieldSecurityIndices map[string]bool) ([]interface{}, error) {
 	if krei == nil {
 		return nil, nil
 	}
@@ -437,7 +676,7 @@ func flattenKibanaRoleElasticsearchMappingsIndices(krei []kbapi.KibanaRoleElasti
 	tfList := make([]interface{}, 0)
 
 	for _, item := range krei {
-		flatten, err := flattenKibanaRoleElasticsearchMappingIndices(item)
+		flatten, err := flattenKibanaRoleElasticsearchMappingIndices(item, jsonFieldSecurityIndices[indiceKey(item.Names, item.Privileges, nil)])
 		if err != nil {
 			return nil, err
 		}
@@ -447,24 +686,128 @@ func flattenKibanaRoleElasticsearchMappingsIndices(krei []kbapi.KibanaRoleElasti
 	return tfList, nil
 }
 
-func flattenKibanaRoleElasticsearchMappingIndices(krei kbapi.KibanaRoleElasticsearchIndice) (map[string]interface{}, error) {
+func flattenKibanaRoleElasticsearchMappingIndices(krei kibanaRoleElasticsearchIndice, isJSONFieldSecurity bool) (map[string]interface{}, error) {
 
 	tfMap := make(map[string]interface{})
 
 	tfMap["names"] = krei.Names
 	tfMap["privileges"] = krei.Privileges
 	tfMap["query"] = krei.Query
+	tfMap["allow_restricted_indices"] = krei.AllowRestrictedIndices
 
-	flattenFieldSecurity, err := convertInterfaceToJsonString(krei.FieldSecurity)
-	if err != nil {
-		return nil, err
+	if isJSONFieldSecurity {
+		flattenFieldSecurity, err := convertInterfaceToJsonString(krei.FieldSecurity)
+		if err != nil {
+			return nil, err
+		}
+		tfMap["field_security"] = flattenFieldSecurity
+	} else {
+		tfMap["field_level_security"] = flattenKibanaRoleFieldSecurity(krei.FieldSecurity)
 	}
-	tfMap["field_security"] = flattenFieldSecurity
 
 	return tfMap, nil
 
 }
 
+func flattenKibanaRoleElasticsearchMappingsRemoteIndices(krei []kibanaRoleElasticsearchRemoteIndice, jsonFieldSecurityIndices map[string]bool) ([]interface{}, error) {
+	if krei == nil {
+		return nil, nil
+	}
+
+	tfList := make([]interface{}, 0)
+
+	for _, item := range krei {
+		flatten, err := flattenKibanaRoleElasticsearchMappingIndices(item.kibanaRoleElasticsearchIndice, jsonFieldSecurityIndices[indiceKey(item.Names, item.Privileges, item.Clusters)])
+		if err != nil {
+			return nil, err
+		}
+		flatten["clusters"] = item.Clusters
+		tfList = append(tfList, flatten)
+	}
+
+	return tfList, nil
+}
+
+func flattenKibanaRoleFieldSecurity(fieldSecurity map[string]interface{}) []interface{} {
+	if len(fieldSecurity) == 0 {
+		return nil
+	}
+
+	tfMap := make(map[string]interface{})
+	for _, key := range []string{"grant", "except"} {
+		switch values := fieldSecurity[key].(type) {
+		case []interface{}:
+			tfMap[key] = values
+		case []string:
+			tfMap[key] = values
+		}
+	}
+
+	return []interface{}{tfMap}
+}
+
+// getIndicesWithJSONFieldSecurity permit to get the indices that use field_security JSON string instead of field_level_security block
+func getIndicesWithJSONFieldSecurity(raws []interface{}) map[string]bool {
+	indices := map[string]bool{}
+	if len(raws) == 0 || raws[0] == nil {
+		return indices
+	}
+
+	raw := raws[0].(map[string]interface{})
+	for _, key := range []string{"indices", "remote_indices"} {
+		if _, ok := raw[key]; !ok {
+			continue
+		}
+		for _, rawIndice := range raw[key].(*schema.Set).List() {
+			m := rawIndice.(map[string]interface{})
+			if m["field_security"].(string) == "" {
+				continue
+			}
+			var clusters []string
+			if rawClusters, ok := m["clusters"]; ok {
+				clusters = convertArrayInterfaceToArrayString(rawClusters.(*schema.Set).List())
+			}
+			indices[indiceKey(
+				convertArrayInterfaceToArrayString(m["names"].(*schema.Set).List()),
+				convertArrayInterfaceToArrayString(m["privileges"].(*schema.Set).List()),
+				clusters,
+			)] = true
+		}
+	}
+
+	return indices
+}
+
+// indiceKey permit to compute a key to identify indice privileges entry
+func indiceKey(names []string, privileges []string, clusters []string) string {
+	items := make([]string, 0, 3)
+	for _, values := range [][]string{names, privileges, clusters} {
+		sortedValues := make([]string, len(values))
+		copy(sortedValues, values)
+		sort.Strings(sortedValues)
+		items = append(items, strings.Join(sortedValues, ","))
+	}
+
+	return strings.Join(items, "|")
+}
+
+func flattenKibanaRoleElasticsearchMappingsRemoteCluster(krerc []kibanaRoleElasticsearchRemoteCluster) []interface{} {
+	if krerc == nil {
+		return nil
+	}
+
+	tfList := make([]interface{}, 0, len(krerc))
+
+	for _, item := range krerc {
+		tfMap := make(map[string]interface{})
+		tfMap["clusters"] = item.Clusters
+		tfMap["privileges"] = item.Privileges
+		tfList = append(tfList, tfMap)
+	}
+
+	return tfList
+}
+
 func flattenKibanaRoleFeatureMappings(krf map[string][]string) []interface{} {
 	if krf == nil {
 		return nil
@@ -0,0 +1,239 @@
+package kb
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceKibanaRoleV0 is the role schema before field_level_security was added
+// It's only used to upgrade the state
+func resourceKibanaRoleV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"elasticsearch": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"indices": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"names": {
+										Type:     schema.TypeSet,
+										Required: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+									"privileges": {
+										Type:     schema.TypeSet,
+										Required: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+									"query": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"field_security": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"allow_restricted_indices": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  false,
+									},
+								},
+							},
+						},
+						"remote_indices": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"clusters": {
+										Type:     schema.TypeSet,
+										Required: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+									"names": {
+										Type:     schema.TypeSet,
+										Required: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+									"privileges": {
+										Type:     schema.TypeSet,
+										Required: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+									"query": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"field_security": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"allow_restricted_indices": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  false,
+									},
+								},
+							},
+						},
+						"cluster": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"remote_cluster": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"clusters": {
+										Type:     schema.TypeSet,
+										Required: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+									"privileges": {
+										Type:     schema.TypeSet,
+										Required: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+								},
+							},
+						},
+						"run_as": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+			"kibana": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"base": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"spaces": {
+							Type:     schema.TypeSet,
+							Required: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"features": {
+							Type:     schema.TypeSet,
+							Optional: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"name": {
+										Type:     schema.TypeString,
+										Required: true,
+									},
+									"permissions": {
+										Type:     schema.TypeSet,
+										Required: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"metadata": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+	}
+}
+
+// resourceKibanaRoleStateUpgradeV0 permit to add field_level_security block on indices
+// The field_security JSON string is kept as is, so read keep it and there are no diff when it's still on code
+func resourceKibanaRoleStateUpgradeV0(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		return rawState, nil
+	}
+	if m, ok := meta.(*providerMeta); ok {
+		name, _ := rawState["name"].(string)
+		ctx = m.logContext(ctx, "kibana_role", name, "")
+	}
+
+	rawElasticsearch, ok := rawState["elasticsearch"].([]interface{})
+	if !ok {
+		return rawState, nil
+	}
+
+	for _, rawItem := range rawElasticsearch {
+		elasticsearch, ok := rawItem.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, key := range []string{"indices", "remote_indices"} {
+			indices, ok := elasticsearch[key].([]interface{})
+			if !ok {
+				continue
+			}
+			for _, rawIndice := range indices {
+				indice, ok := rawIndice.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				upgradeFieldSecurityV0(ctx, indice)
+			}
+		}
+	}
+
+	return rawState, nil
+}
+
+// upgradeFieldSecurityV0 permit to add empty field_level_security block on indice that not use field_security JSON string
+// When field_security is moved to field_level_security block on code, the next apply convert it and read fill the block
+func upgradeFieldSecurityV0(ctx context.Context, indice map[string]interface{}) {
+	if fieldSecurityJSON, _ := indice["field_security"].(string); fieldSecurityJSON != "" {
+		tflog.Debug(ctx, "Keep field_security as JSON string", map[string]interface{}{"field_security": fieldSecurityJSON})
+		return
+	}
+
+	indice["field_level_security"] = []interface{}{}
+}
@@ -1,11 +1,15 @@
 package kb
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"testing"
 
-	kibana "github.com/disaster37/go-kibana-rest/v8"
+	"github.com/coreos/go-semver/semver"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/pkg/errors"
 )
@@ -36,6 +40,259 @@ func TestAccKibanaRole(t *testing.T) {
 
 }
 
+func TestKibanaRoleElasticsearchRemotePrivileges(t *testing.T) {
+	raw := map[string]interface{}{
+		"name": "test",
+		"elasticsearch": []interface{}{
+			map[string]interface{}{
+				"cluster": []interface{}{"monitor"},
+				"remote_indices": []interface{}{
+					map[string]interface{}{
+						"clusters":                 []interface{}{"remote-*"},
+						"names":                    []interface{}{"logstash-*"},
+						"privileges":               []interface{}{"read"},
+						"allow_restricted_indices": true,
+					},
+				},
+				"remote_cluster": []interface{}{
+					map[string]interface{}{
+						"clusters":   []interface{}{"remote-*"},
+						"privileges": []interface{}{"monitor_enrich"},
+					},
+				},
+			},
+		},
+	}
+	d := schema.TestResourceDataRaw(t, resourceKibanaRole().Schema, raw)
+
+	kre, err := buildRolesElasticsearch(d.Get("elasticsearch").([]interface{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(kre)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"remote_indices":[{"names":["logstash-*"],"privileges":["read"],"query":"","allow_restricted_indices":true,"clusters":["remote-*"]}],"cluster":["monitor"],"remote_cluster":[{"clusters":["remote-*"],"privileges":["monitor_enrich"]}]}`
+	if string(payload) != expected {
+		t.Errorf("Unexpected payload:\n%s\nexpected:\n%s", payload, expected)
+	}
+
+	flatten, err := flattenKibanaRoleElasticsearchMappings(kre, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = d.Set("elasticsearch", flatten); err != nil {
+		t.Fatal(err)
+	}
+	roundTrip, err := buildRolesElasticsearch(d.Get("elasticsearch").([]interface{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(kre, roundTrip) {
+		t.Errorf("Round trip failed:\n%+v\nexpected:\n%+v", roundTrip, kre)
+	}
+}
+
+func TestKibanaRoleFieldLevelSecurity(t *testing.T) {
+	raw := map[string]interface{}{
+		"name": "test",
+		"elasticsearch": []interface{}{
+			map[string]interface{}{
+				"indices": []interface{}{
+					map[string]interface{}{
+						"names":      []interface{}{"logstash-*"},
+						"privileges": []interface{}{"read"},
+						"field_level_security": []interface{}{
+							map[string]interface{}{
+								"grant":  []interface{}{"*"},
+								"except": []interface{}{"secret"},
+							},
+						},
+					},
+					map[string]interface{}{
+						"names":          []interface{}{"filebeat-*"},
+						"privileges":     []interface{}{"read"},
+						"field_security": `{"grant": ["message"]}`,
+					},
+				},
+			},
+		},
+	}
+	d := schema.TestResourceDataRaw(t, resourceKibanaRole().Schema, raw)
+
+	kre, err := buildRolesElasticsearch(d.Get("elasticsearch").([]interface{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, indice := range kre.Indices {
+		payload, err := json.Marshal(indice.FieldSecurity)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch indice.Names[0] {
+		case "logstash-*":
+			if string(payload) != `{"except":["secret"],"grant":["*"]}` {
+				t.Errorf("Unexpected field security for logstash-*: %s", payload)
+			}
+		case "filebeat-*":
+			if string(payload) != `{"grant":["message"]}` {
+				t.Errorf("Unexpected field security for filebeat-*: %s", payload)
+			}
+		}
+	}
+
+	// Indices keep the field security format used on state
+	flatten, err := flattenKibanaRoleElasticsearchMappings(kre, getIndicesWithJSONFieldSecurity(d.Get("elasticsearch").([]interface{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rawIndice := range flatten[0].(map[string]interface{})["indices"].([]interface{}) {
+		indice := rawIndice.(map[string]interface{})
+		_, hasJSON := indice["field_security"]
+		_, hasBlock := indice["field_level_security"]
+		switch indice["names"].([]string)[0] {
+		case "logstash-*":
+			if hasJSON || !hasBlock {
+				t.Errorf("Expected field_level_security block for logstash-*, got %+v", indice)
+			}
+		case "filebeat-*":
+			if !hasJSON || hasBlock {
+				t.Errorf("Expected field_security string for filebeat-*, got %+v", indice)
+			}
+		}
+	}
+
+	// Can't use both format on same indice
+	raw["elasticsearch"].([]interface{})[0].(map[string]interface{})["indices"].([]interface{})[1].(map[string]interface{})["field_level_security"] = []interface{}{
+		map[string]interface{}{
+			"grant": []interface{}{"*"},
+		},
+	}
+	d = schema.TestResourceDataRaw(t, resourceKibanaRole().Schema, raw)
+	if _, err = buildRolesElasticsearch(d.Get("elasticsearch").([]interface{})); err == nil {
+		t.Error("Expected error when field_security and field_level_security are set")
+	}
+}
+
+func TestKibanaRoleStateUpgradeV0(t *testing.T) {
+	rawState := map[string]interface{}{
+		"name": "test",
+		"elasticsearch": []interface{}{
+			map[string]interface{}{
+				"indices": []interface{}{
+					map[string]interface{}{
+						"names":          []interface{}{"logstash-*"},
+						"privileges":     []interface{}{"read"},
+						"field_security": `{"grant":["*"],"except":["secret"]}`,
+					},
+					map[string]interface{}{
+						"names":          []interface{}{"metricbeat-*"},
+						"privileges":     []interface{}{"read"},
+						"field_security": "",
+					},
+				},
+			},
+		},
+	}
+
+	upgradedState, err := resourceKibanaRoleStateUpgradeV0(context.Background(), rawState, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	indices := upgradedState["elasticsearch"].([]interface{})[0].(map[string]interface{})["indices"].([]interface{})
+
+	// The field_security JSON string is kept as is
+	expected := map[string]interface{}{
+		"names":          []interface{}{"logstash-*"},
+		"privileges":     []interface{}{"read"},
+		"field_security": `{"grant":["*"],"except":["secret"]}`,
+	}
+	if !reflect.DeepEqual(indices[0], expected) {
+		t.Errorf("Expected %+v, got %+v", expected, indices[0])
+	}
+
+	// The indice without field_security get empty field_level_security block
+	expected = map[string]interface{}{
+		"names":                []interface{}{"metricbeat-*"},
+		"privileges":           []interface{}{"read"},
+		"field_security":       "",
+		"field_level_security": []interface{}{},
+	}
+	if !reflect.DeepEqual(indices[1], expected) {
+		t.Errorf("Expected %+v, got %+v", expected, indices[1])
+	}
+}
+
+func TestKibanaRoleValidatePrivileges(t *testing.T) {
+	testCases := []struct {
+		name          string
+		elasticsearch []interface{}
+		isError       bool
+	}{
+		{
+			name: "valid privileges",
+			elasticsearch: []interface{}{
+				map[string]interface{}{
+					"cluster": []interface{}{"monitor", "cluster:monitor/main"},
+					"indices": []interface{}{
+						map[string]interface{}{
+							"names":      []interface{}{"logstash-*"},
+							"privileges": []interface{}{"read", "indices:data/read/*"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "unknown cluster privilege",
+			elasticsearch: []interface{}{
+				map[string]interface{}{
+					"cluster": []interface{}{"monitr"},
+				},
+			},
+			isError: true,
+		},
+		{
+			name: "unknown index privilege",
+			elasticsearch: []interface{}{
+				map[string]interface{}{
+					"indices": []interface{}{
+						map[string]interface{}{
+							"names":      []interface{}{"logstash-*"},
+							"privileges": []interface{}{"reed"},
+						},
+					},
+				},
+			},
+			isError: true,
+		},
+		{
+			name: "multiple elasticsearch blocks",
+			elasticsearch: []interface{}{
+				map[string]interface{}{
+					"cluster": []interface{}{"monitor"},
+				},
+				map[string]interface{}{
+					"cluster": []interface{}{"all"},
+				},
+			},
+			isError: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		diags := resourceKibanaRole().Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name":          "test",
+			"elasticsearch": testCase.elasticsearch,
+		}))
+		if diags.HasError() != testCase.isError {
+			t.Errorf("%s: expected error %t, got %+v", testCase.name, testCase.isError, diags)
+		}
+	}
+}
+
 func testCheckKibanaRoleExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -48,7 +305,7 @@ func testCheckKibanaRoleExists(name string) resource.TestCheckFunc {
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*kibana.Client)
+		client := meta.(*providerMeta).client
 		role, err := client.API.KibanaRoleManagement.Get(rs.Primary.ID)
 		if err != nil {
 			return err
@@ -69,7 +326,7 @@ func testCheckKibanaRoleDestroy(s *terraform.State) error {
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*kibana.Client)
+		client := meta.(*providerMeta).client
 		role, err := client.API.KibanaRoleManagement.Get(rs.Primary.ID)
 		if err != nil {
 			return err
@@ -93,8 +350,13 @@ resource kibana_role "test" {
 		privileges 	= ["read"]
 	}
 	indices {
-		names 		= ["logstash-*"]
+		names 		= [".security*"]
 		privileges 	= ["read"]
+		allow_restricted_indices = true
+		field_level_security {
+			grant  = ["*"]
+			except = ["password"]
+		}
 	}
 	cluster = ["all"]
   }
@@ -109,5 +371,78 @@ resource kibana_role "test" {
 	  }
 	  spaces = ["default"]
   }
+  kibana {
+	  base 	 = ["read"]
+	  spaces = ["terraform-test"]
+  }
 }
 `
+
+func TestKibanaRoleMinimalVersion(t *testing.T) {
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name": "terraform-test",
+		"elasticsearch": []interface{}{
+			map[string]interface{}{
+				"remote_indices": []interface{}{
+					map[string]interface{}{
+						"clusters":   []interface{}{"remote"},
+						"names":      []interface{}{"logstash-*"},
+						"privileges": []interface{}{"read"},
+					},
+				},
+			},
+		},
+	})
+
+	testCases := []struct {
+		version string
+		isError bool
+	}{
+		{version: "8.5.0", isError: true},
+		{version: "8.10.0-SNAPSHOT", isError: false},
+		{version: "8.10.0", isError: false},
+	}
+
+	for _, testCase := range testCases {
+		meta := &providerMeta{version: semver.New(testCase.version)}
+		_, err := resourceKibanaRole().Diff(context.Background(), nil, config, meta)
+		if testCase.isError && err == nil {
+			t.Errorf("remote_indices on Kibana %s must return error", testCase.version)
+		}
+		if !testCase.isError && err != nil {
+			t.Errorf("Unexpected error on Kibana %s: %s", testCase.version, err.Error())
+		}
+	}
+
+	// Unknown version skip the check
+	if _, err := resourceKibanaRole().Diff(context.Background(), nil, config, &providerMeta{}); err != nil {
+		t.Errorf("Unexpected error when version is unknown: %s", err.Error())
+	}
+
+	// With lazy connect, the version is resolved at plan time
+	server := newTestKibanaServer(t, nil)
+	meta, diags := providerConfigure(context.Background(), schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url":          server.URL,
+		"retry":        0,
+		"lazy_connect": true,
+	}))
+	if diags.HasError() {
+		t.Fatalf("Unexpected error: %+v", diags)
+	}
+	if _, err := resourceKibanaRole().Diff(context.Background(), nil, config, meta); err == nil {
+		t.Errorf("remote_indices on Kibana 8.5.0 must return error with lazy connect")
+	}
+
+	// With lazy connect, the check is skipped when Kibana is down
+	meta, diags = providerConfigure(context.Background(), schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url":          "http://127.0.0.1:1",
+		"retry":        0,
+		"lazy_connect": true,
+	}))
+	if diags.HasError() {
+		t.Fatalf("Unexpected error: %+v", diags)
+	}
+	if _, err := resourceKibanaRole().Diff(context.Background(), nil, config, meta); err != nil {
+		t.Errorf("Unexpected error when Kibana is down with lazy connect: %s", err.Error())
+	}
+}
@@ -7,13 +7,22 @@ package kb
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	kibana "github.com/disaster37/go-kibana-rest/v8"
 	kbapi "github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	log "github.com/sirupsen/logrus"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/pkg/errors"
 )
 
 // Resource specification to handle user space in Kibana
@@ -24,10 +33,25 @@ func resourceKibanaUserSpace() *schema.Resource {
 		UpdateContext: resourceKibanaUserSpaceUpdate,
 		DeleteContext: resourceKibanaUserSpaceDelete,
 
+		CustomizeDiff: customizeDiffMinimalVersion(
+			versionRequirement{
+				attribute: "solution",
+				version:   "8.16.0",
+				isUsed:    isAttributeSet("solution"),
+			},
+		),
+
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Read:   schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"uid": {
 				Type:     schema.TypeString,
@@ -57,6 +81,34 @@ func resourceKibanaUserSpace() *schema.Resource {
 				Type:     schema.TypeString,
 				Optional: true,
 			},
+			"image_url": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentImageURL,
+			},
+			"solution": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice([]string{"classic", "es", "oblt", "security"}, false),
+			},
+			"settings": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"default_route": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"default_index": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -69,27 +121,39 @@ func resourceKibanaUserSpaceCreate(ctx context.Context, d *schema.ResourceData,
 	disabledFeatures := convertArrayInterfaceToArrayString(d.Get("disabled_features").(*schema.Set).List())
 	initials := d.Get("initials").(string)
 	color := d.Get("color").(string)
+	solution := d.Get("solution").(string)
+	imageURL, err := imageDataURL(d.Get("image_url").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
-	client := meta.(*kibana.Client)
-
-	userSpace := &kbapi.KibanaSpace{
-		ID:               id,
-		Name:             name,
-		Description:      description,
-		DisabledFeatures: disabledFeatures,
-		Initials:         initials,
-		Color:            color,
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_user_space", d.Id(), "")
+	client := meta.(*providerMeta).clientWithContext(ctx)
+
+	userSpace := &kibanaSpace{
+		KibanaSpace: kbapi.KibanaSpace{
+			ID:               id,
+			Name:             name,
+			Description:      description,
+			DisabledFeatures: disabledFeatures,
+			Initials:         initials,
+			Color:            color,
+		},
+		ImageURL: imageURL,
+		Solution: solution,
 	}
 
-	_, err := client.API.KibanaSpaces.Create(userSpace)
-	if err != nil {
+	if err = createKibanaSpace(ctx, client.Client, userSpace); err != nil {
 		return diag.FromErr(err)
 	}
 
 	d.SetId(id)
 
-	log.Infof("Created user space %s (%s) successfully", id, name)
-	fmt.Printf("[INFO] Created user space %s (%s) successfully", id, name)
+	if err = updateKibanaUserSpaceSettings(ctx, client, id, nil, d.Get("settings").([]interface{})); err != nil {
+		return diag.FromErr(err)
+	}
+
+	tflog.Info(ctx, "Created user space successfully", map[string]interface{}{"id": id, "name": name})
 
 	return resourceKibanaUserSpaceRead(ctx, d, meta)
 }
@@ -100,23 +164,21 @@ func resourceKibanaUserSpaceRead(ctx context.Context, d *schema.ResourceData, me
 	var err error
 	id := d.Id()
 
-	log.Debugf("User space id:  %s", id)
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_user_space", d.Id(), "")
+	client := meta.(*providerMeta).clientWithContext(ctx)
 
-	client := meta.(*kibana.Client)
-
-	userSpace, err := client.API.KibanaSpaces.Get(id)
+	userSpace, err := getKibanaSpace(ctx, client.Client, id)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
 	if userSpace == nil {
-		log.Warnf("User space %s not found - removing from state", id)
-		fmt.Printf("[WARN] User space %s not found - removing from state", id)
+		tflog.Warn(ctx, "User space not found - removing from state", map[string]interface{}{"id": id})
 		d.SetId("")
 		return nil
 	}
 
-	log.Debugf("Get user space %s successfully:\n%s", id, userSpace)
+	tflog.Debug(ctx, "Get user space successfully", map[string]interface{}{"id": id, "user_space": userSpace.String()})
 
 	if err = d.Set("uid", id); err != nil {
 		return diag.FromErr(err)
@@ -136,9 +198,25 @@ func resourceKibanaUserSpaceRead(ctx context.Context, d *schema.ResourceData, me
 	if err = d.Set("color", userSpace.Color); err != nil {
 		return diag.FromErr(err)
 	}
+	if err = d.Set("image_url", userSpace.ImageURL); err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("solution", userSpace.Solution); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Only the managed settings are read, Kibana can set the default index when the first data view is created
+	if managedSettings := d.Get("settings").([]interface{}); len(managedSettings) > 0 && managedSettings[0] != nil {
+		settings, err := getKibanaSettings(ctx, client.Client, id, false)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err = d.Set("settings", flattenUserSpaceSettings(settings, managedSettings)); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 
-	log.Infof("Read user space %s successfully", id)
-	fmt.Printf("[INFO] Read user space %s successfully", id)
+	tflog.Info(ctx, "Read user space successfully", map[string]interface{}{"id": id})
 
 	return nil
 }
@@ -151,24 +229,40 @@ func resourceKibanaUserSpaceUpdate(ctx context.Context, d *schema.ResourceData,
 	disabledFeatures := convertArrayInterfaceToArrayString(d.Get("disabled_features").(*schema.Set).List())
 	initials := d.Get("initials").(string)
 	color := d.Get("color").(string)
+	solution := d.Get("solution").(string)
+	imageURL, err := imageDataURL(d.Get("image_url").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
-	client := meta.(*kibana.Client)
-	userSpace := &kbapi.KibanaSpace{
-		ID:               id,
-		Name:             name,
-		Description:      description,
-		DisabledFeatures: disabledFeatures,
-		Initials:         initials,
-		Color:            color,
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_user_space", d.Id(), "")
+	client := meta.(*providerMeta).clientWithContext(ctx)
+	userSpace := &kibanaSpace{
+		KibanaSpace: kbapi.KibanaSpace{
+			ID:               id,
+			Name:             name,
+			Description:      description,
+			DisabledFeatures: disabledFeatures,
+			Initials:         initials,
+			Color:            color,
+		},
+		ImageURL: imageURL,
+		Solution: solution,
 	}
 
-	_, err := client.API.KibanaSpaces.Update(userSpace)
-	if err != nil {
+	if err = updateKibanaSpace(ctx, client.Client, userSpace); err != nil {
 		return diag.FromErr(err)
 	}
 
-	log.Infof("Updated user space %s successfully", id)
-	fmt.Printf("[INFO] Updated user space %s successfully", id)
+	// Settings are only applied when they change, the removed settings are reset
+	if d.HasChange("settings") {
+		oldSettings, newSettings := d.GetChange("settings")
+		if err = updateKibanaUserSpaceSettings(ctx, client, id, oldSettings.([]interface{}), newSettings.([]interface{})); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	tflog.Info(ctx, "Updated user space successfully", map[string]interface{}{"id": id})
 
 	return resourceKibanaUserSpaceRead(ctx, d, meta)
 }
@@ -177,15 +271,14 @@ func resourceKibanaUserSpaceUpdate(ctx context.Context, d *schema.ResourceData,
 func resourceKibanaUserSpaceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 
 	id := d.Id()
-	log.Debugf("User space id: %s", id)
 
-	client := meta.(*kibana.Client)
+	ctx = meta.(*providerMeta).logContext(ctx, "kibana_user_space", d.Id(), "")
+	client := meta.(*providerMeta).clientWithContext(ctx)
 
 	err := client.API.KibanaSpaces.Delete(id)
 	if err != nil {
 		if err.(kbapi.APIError).Code == 404 {
-			log.Warnf("User space %s not found - removing from state", id)
-			fmt.Printf("[WARN] User space %s not found - removing from state", id)
+			tflog.Warn(ctx, "User space not found - removing from state", map[string]interface{}{"id": id})
 			d.SetId("")
 			return nil
 		}
@@ -195,8 +288,91 @@ func resourceKibanaUserSpaceDelete(ctx context.Context, d *schema.ResourceData,
 
 	d.SetId("")
 
-	log.Infof("Deleted user space %s successfully", id)
-	fmt.Printf("[INFO] Deleted user space %s successfully", id)
+	tflog.Info(ctx, "Deleted user space successfully", map[string]interface{}{"id": id})
 	return nil
 
 }
+
+// userSpaceSettingKeys is the advanced settings keys of user space settings attributes
+var userSpaceSettingKeys = map[string]string{
+	"default_route": "defaultRoute",
+	"default_index": "defaultIndex",
+}
+
+// updateKibanaUserSpaceSettings permit to apply the default settings on user space
+// The settings removed from configuration are reset to default value
+func updateKibanaUserSpaceSettings(ctx context.Context, client *kibana.Client, id string, oldRaws []interface{}, newRaws []interface{}) error {
+	oldSettings := expandUserSpaceSettings(oldRaws)
+	newSettings := expandUserSpaceSettings(newRaws)
+
+	changes := map[string]interface{}{}
+	for attribute, key := range userSpaceSettingKeys {
+		if newSettings[attribute] != "" {
+			changes[key] = newSettings[attribute]
+		} else if oldSettings[attribute] != "" {
+			changes[key] = nil
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if err := updateKibanaSettings(ctx, client.Client, changes, id, false); err != nil {
+		return errors.Wrapf(err, "Error when apply settings on user space %s", id)
+	}
+
+	tflog.Debug(ctx, "Applied settings on user space", map[string]interface{}{"id": id, "changes": changes})
+
+	return nil
+}
+
+// expandUserSpaceSettings permit to get the settings block as map
+func expandUserSpaceSettings(raws []interface{}) map[string]string {
+	settings := map[string]string{}
+	if len(raws) == 0 || raws[0] == nil {
+		return settings
+	}
+
+	for attribute, value := range raws[0].(map[string]interface{}) {
+		settings[attribute], _ = value.(string)
+	}
+
+	return settings
+}
+
+// flattenUserSpaceSettings permit to convert the advanced settings of user space as settings block
+// Only the settings set on state are read, to not show the settings managed elsewhere as drift
+func flattenUserSpaceSettings(settings map[string]kibanaSetting, managedRaws []interface{}) []interface{} {
+	managedSettings := expandUserSpaceSettings(managedRaws)
+
+	tfMap := map[string]interface{}{}
+	for attribute, key := range userSpaceSettingKeys {
+		value := ""
+		if managedSettings[attribute] != "" {
+			value, _ = settings[key].UserValue.(string)
+		}
+		tfMap[attribute] = value
+	}
+
+	return []interface{}{tfMap}
+}
+
+// imageDataURL permit to convert the image file as data URL expected by Kibana
+// It return the value as is if it's already a data URL
+func imageDataURL(value string) (string, error) {
+	if value == "" || strings.HasPrefix(value, "data:") {
+		return value, nil
+	}
+
+	content, err := os.ReadFile(value)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error when read image file %s", value)
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(value))
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+
+	return fmt.Sprintf("data:%s;base64,%s", contentType, base64.StdEncoding.EncodeToString(content)), nil
+}
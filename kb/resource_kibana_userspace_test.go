@@ -1,11 +1,16 @@
 package kb
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 
-	kibana "github.com/disaster37/go-kibana-rest/v8"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/pkg/errors"
 )
@@ -23,18 +28,128 @@ func TestAccKibanaUserSpace(t *testing.T) {
 				Config: testKibanaUserSpace,
 				Check: resource.ComposeTestCheckFunc(
 					testCheckKibanaUserSpaceExists("kibana_user_space.test"),
+					resource.TestCheckResourceAttr("kibana_user_space.test", "image_url", testKibanaUserSpaceImageURL),
 				),
 			},
 			{
 				ResourceName:            "kibana_user_space.test",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{},
+				ImportStateVerifyIgnore: []string{"settings"},
 			},
 		},
 	})
 }
 
+func TestKibanaUserSpaceRead(t *testing.T) {
+	meta := newTestProviderMeta(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/spaces/space/terraform-test":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id": "terraform-test", "name": "terraform-test", "disabledFeatures": ["canvas"], "solution": "oblt"}`))
+		case "/s/terraform-test/api/kibana/settings":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"settings": {"defaultRoute": {"userValue": "/app/dashboards"}, "defaultIndex": {"userValue": "logs"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	// Existing space
+	d := schema.TestResourceDataRaw(t, resourceKibanaUserSpace().Schema, map[string]interface{}{})
+	d.SetId("terraform-test")
+	if diags := resourceKibanaUserSpaceRead(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("Unexpected error: %+v", diags)
+	}
+	if d.Get("name").(string) != "terraform-test" || d.Get("solution").(string) != "oblt" || d.Get("disabled_features").(*schema.Set).Len() != 1 {
+		t.Errorf("Unexpected state %+v", d.State())
+	}
+
+	// Only managed settings are read
+	d = schema.TestResourceDataRaw(t, resourceKibanaUserSpace().Schema, map[string]interface{}{
+		"settings": []interface{}{map[string]interface{}{"default_route": "/app/discover"}},
+	})
+	d.SetId("terraform-test")
+	if diags := resourceKibanaUserSpaceRead(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("Unexpected error: %+v", diags)
+	}
+	if d.Get("settings.0.default_route").(string) != "/app/dashboards" || d.Get("settings.0.default_index").(string) != "" {
+		t.Errorf("Unexpected settings %+v", d.Get("settings"))
+	}
+
+	// Space removed from Kibana
+	d = schema.TestResourceDataRaw(t, resourceKibanaUserSpace().Schema, map[string]interface{}{})
+	d.SetId("removed")
+	if diags := resourceKibanaUserSpaceRead(context.Background(), d, meta); diags.HasError() {
+		t.Fatalf("Unexpected error: %+v", diags)
+	}
+	if d.Id() != "" {
+		t.Errorf("Removed space must be removed from state")
+	}
+}
+
+func TestKibanaUserSpaceUpdateSettings(t *testing.T) {
+	var payload string
+	meta := newTestProviderMeta(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		payload = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"settings": {}}`))
+	})
+	oldSettings := []interface{}{map[string]interface{}{"default_route": "/app/discover", "default_index": "logs"}}
+
+	// The settings removed from configuration are reset
+	if err := updateKibanaUserSpaceSettings(context.Background(), meta.client, "terraform-test", oldSettings, []interface{}{map[string]interface{}{"default_route": "/app/dashboards", "default_index": ""}}); err != nil {
+		t.Fatal(err)
+	}
+	if payload != `{"changes":{"defaultIndex":null,"defaultRoute":"/app/dashboards"}}` {
+		t.Errorf("Unexpected payload %s", payload)
+	}
+
+	// The settings block is removed
+	if err := updateKibanaUserSpaceSettings(context.Background(), meta.client, "terraform-test", oldSettings, nil); err != nil {
+		t.Fatal(err)
+	}
+	if payload != `{"changes":{"defaultIndex":null,"defaultRoute":null}}` {
+		t.Errorf("Unexpected payload %s", payload)
+	}
+}
+
+func TestKibanaUserSpaceImageDataURL(t *testing.T) {
+	// Data URL is kept as is
+	dataURL, err := imageDataURL(testKibanaUserSpaceImageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dataURL != testKibanaUserSpaceImageURL {
+		t.Errorf("Unexpected data URL %s", dataURL)
+	}
+
+	// File is converted as data URL
+	file := filepath.Join(t.TempDir(), "avatar.png")
+	if err = os.WriteFile(file, []byte("avatar"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	dataURL, err = imageDataURL(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dataURL != "data:image/png;base64,YXZhdGFy" {
+		t.Errorf("Unexpected data URL %s", dataURL)
+	}
+	if !suppressEquivalentImageURL("image_url", dataURL, file, nil) {
+		t.Errorf("Image file %s must be equivalent to %s", file, dataURL)
+	}
+	if suppressEquivalentImageURL("image_url", testKibanaUserSpaceImageURL, file, nil) {
+		t.Errorf("Image file %s must not be equivalent to %s", file, testKibanaUserSpaceImageURL)
+	}
+
+	// Missing file
+	if _, err = imageDataURL(filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Error("Missing image file must return error")
+	}
+}
+
 func testCheckKibanaUserSpaceExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -47,7 +162,7 @@ func testCheckKibanaUserSpaceExists(name string) resource.TestCheckFunc {
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*kibana.Client)
+		client := meta.(*providerMeta).client
 		userSpace, err := client.API.KibanaSpaces.Get(rs.Primary.ID)
 		if err != nil {
 			return err
@@ -68,7 +183,7 @@ func testCheckKibanaUserSpaceDestroy(s *terraform.State) error {
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*kibana.Client)
+		client := meta.(*providerMeta).client
 		userSpace, err := client.API.KibanaSpaces.Get(rs.Primary.ID)
 		if err != nil {
 			return err
@@ -91,5 +206,11 @@ resource "kibana_user_space" "test" {
   initials			= "tt"
   color				= "#000000"
   disabled_features = ["canvas", "maps", "advancedSettings", "indexPatterns", "graph", "monitoring", "ml", "apm", "infrastructure", "logs", "siem"]
+  image_url         = "` + testKibanaUserSpaceImageURL + `"
+  settings {
+    default_route = "/app/discover"
+  }
 }
 `
+
+var testKibanaUserSpaceImageURL = "data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk+M9QDwADhgGAWjR9awAAAABJRU5ErkJggg=="
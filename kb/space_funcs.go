@@ -0,0 +1,46 @@
+package kb
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const defaultKibanaSpace = "default"
+
+// customizeDiffDefaultSpace permit to use the provider default space when the space attribute is not set on resource
+// When the attribute is set on configuration, it always win
+func customizeDiffDefaultSpace(key string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		if isSpaceSetOnConfig(d, key) {
+			return nil
+		}
+
+		space := providerDefaultSpace(meta)
+		if d.Get(key).(string) == space {
+			return nil
+		}
+
+		return d.SetNew(key, space)
+	}
+}
+
+// isSpaceSetOnConfig return true if the space attribute is set on configuration
+// When the raw configuration is not available, it fallback on the current value
+func isSpaceSetOnConfig(d *schema.ResourceDiff, key string) bool {
+	rawConfig := d.GetRawConfig()
+	if rawConfig.IsNull() || !rawConfig.IsKnown() {
+		return d.Get(key).(string) != ""
+	}
+
+	return !rawConfig.GetAttr(key).IsNull()
+}
+
+// providerDefaultSpace return the default space set on provider
+func providerDefaultSpace(meta interface{}) string {
+	if m, ok := meta.(*providerMeta); ok && m.defaultSpace != "" {
+		return m.defaultSpace
+	}
+
+	return defaultKibanaSpace
+}
@@ -0,0 +1,194 @@
+package kb
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// debugBodyMaxLength is the maximum length of request and response bodies on logs, like for big saved objects export
+const debugBodyMaxLength = 64 * 1024
+
+// redactedValue is the value logged in place of sensitive values
+const redactedValue = "***"
+
+// debugTransport is the HTTP transport that log all API calls, with the credentials and the secrets redacted
+// It's enabled with debug provider flag, and the logs are visible with TF_LOG_PROVIDER=DEBUG
+type debugTransport struct {
+	next            http.RoundTripper
+	sensitiveValues []string
+}
+
+// newDebugTransport wrap the transport to log the API calls
+// The sensitive values, like the credentials set on provider, are redacted on headers and bodies
+func newDebugTransport(next http.RoundTripper, sensitiveValues []string) *debugTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &debugTransport{
+		next:            next,
+		sensitiveValues: sensitiveValues,
+	}
+}
+
+// RoundTrip log the request and the response, with the latency of API call
+// The bodies are read before they are sent to the next transport, so they must be restored
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	requestBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	res, err := t.next.RoundTrip(req)
+	fields := map[string]interface{}{
+		"method":          req.Method,
+		"url":             req.URL.String(),
+		"latency":         time.Since(start).String(),
+		"request_headers": t.redactHeaders(req.Header),
+		"request_body":    t.redactBody(requestBody),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.Debug(ctx, "Kibana API call failed", fields)
+		return res, err
+	}
+
+	responseBody, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	fields["status"] = res.StatusCode
+	fields["response_body"] = t.redactBody(responseBody)
+	tflog.Debug(ctx, "Kibana API call", fields)
+
+	return res, nil
+}
+
+// readRequestBody return a copy of request body
+// When the body can't be read twice, it's read and restored on request
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+
+	payload, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(payload))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(payload)), nil
+	}
+
+	return payload, nil
+}
+
+// redactHeaders return the headers with credentials redacted
+// The authorization scheme is kept, so we know if basic auth, API key or bearer token is used
+func (t *debugTransport) redactHeaders(headers http.Header) map[string]string {
+	redactedHeaders := make(map[string]string, len(headers))
+	for key, values := range headers {
+		value := strings.Join(values, ", ")
+		if strings.EqualFold(key, "Authorization") {
+			scheme, _, _ := strings.Cut(value, " ")
+			value = scheme + " " + redactedValue
+		}
+		redactedHeaders[key] = t.redactString(value)
+	}
+
+	return redactedHeaders
+}
+
+// redactBody return the body with secrets redacted
+// On JSON body, the values of sensitive keys are redacted, like the connector secrets
+func (t *debugTransport) redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err == nil {
+		if redactedBody, err := json.Marshal(redactJSONValue(data)); err == nil {
+			body = redactedBody
+		}
+	}
+
+	return truncateLogValue(t.redactString(string(body)), debugBodyMaxLength)
+}
+
+// truncateLogValue permit to cut the value to max length, on rune boundary to keep valid UTF-8
+func truncateLogValue(value string, maxLength int) string {
+	if len(value) <= maxLength {
+		return value
+	}
+
+	end := maxLength
+	for end > 0 && !utf8.RuneStart(value[end]) {
+		end--
+	}
+
+	return value[:end] + "... (truncated)"
+}
+
+// redactString replace the sensitive values
+func (t *debugTransport) redactString(value string) string {
+	for _, sensitiveValue := range t.sensitiveValues {
+		value = strings.ReplaceAll(value, sensitiveValue, redactedValue)
+	}
+
+	return value
+}
+
+// redactJSONValue redact the values of sensitive keys, on all nested objects
+func redactJSONValue(data interface{}) interface{} {
+	switch value := data.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			if isSensitiveLogField(key) {
+				value[key] = redactedValue
+			} else {
+				value[key] = redactJSONValue(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = redactJSONValue(item)
+		}
+	}
+
+	return data
+}
+
+// isSensitiveLogField return true if the key is one of sensitive log fields
+func isSensitiveLogField(key string) bool {
+	for _, field := range sensitiveLogFields {
+		if strings.EqualFold(field, key) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,117 @@
+package kb
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestDebugTransport(t *testing.T) {
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	server := newTestKibanaServer(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url":      server.URL,
+		"username": "elastic",
+		"password": "changeme",
+		"headers":  map[string]interface{}{"X-Proxy-Token": "proxy-secret"},
+		"debug":    true,
+	})
+	meta, diags := providerConfigure(ctx, d)
+	if diags.HasError() {
+		t.Fatalf("Unexpected error: %+v", diags)
+	}
+	client := meta.(*providerMeta).clientWithContext(ctx).Client
+
+	_, err := client.R().
+		SetBody(map[string]interface{}{
+			"name":    "test",
+			"config":  map[string]interface{}{"url": "https://test"},
+			"secrets": map[string]interface{}{"token": "connector-secret"},
+		}).
+		Post("/api/actions/connector")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry map[string]interface{}
+	for _, e := range entries {
+		if e["@message"] == "Kibana API call" && e["method"] == http.MethodPost {
+			entry = e
+		}
+	}
+	if entry == nil {
+		t.Fatalf("API call must be logged, got %+v", entries)
+	}
+	if entry["status"] != float64(http.StatusOK) || entry["latency"] == nil || !strings.HasSuffix(entry["url"].(string), "/api/actions/connector") {
+		t.Errorf("Unexpected API call log %+v", entry)
+	}
+	if headers := entry["request_headers"].(map[string]interface{}); headers["Authorization"] != "Basic ***" || headers["X-Proxy-Token"] != "***" {
+		t.Errorf("Credentials must be redacted on headers, got %+v", headers)
+	}
+	if body := entry["request_body"].(string); !strings.Contains(body, `"secrets":"***"`) || !strings.Contains(body, "https://test") {
+		t.Errorf("Connector secrets must be redacted on body, got %s", body)
+	}
+	for _, secret := range []string{"changeme", "proxy-secret", "connector-secret"} {
+		if strings.Contains(output.String(), secret) {
+			t.Errorf("Secret %s must not be logged", secret)
+		}
+	}
+
+	// The API calls are not logged without debug
+	output.Reset()
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url": server.URL,
+	})
+	if _, diags = providerConfigure(ctx, d); diags.HasError() {
+		t.Fatalf("Unexpected error: %+v", diags)
+	}
+	if strings.Contains(output.String(), "Kibana API call") {
+		t.Errorf("API calls must not be logged without debug")
+	}
+}
+
+func TestDebugTransportRedactBody(t *testing.T) {
+	transport := newDebugTransport(nil, []string{"api-key"})
+
+	testCases := map[string]string{
+		``:                                  ``,
+		`not json with api-key`:             `not json with ***`,
+		`{"password":"test","name":"test"}`: `{"name":"test","password":"***"}`,
+		`[{"attributes":{"secrets":{"user":"test"}}}]`: `[{"attributes":{"secrets":"***"}}]`,
+	}
+	for body, expected := range testCases {
+		if redactedBody := transport.redactBody([]byte(body)); redactedBody != expected {
+			t.Errorf("Expected %s, got %s", expected, redactedBody)
+		}
+	}
+}
+
+func TestTruncateLogValue(t *testing.T) {
+	testCases := map[string]string{
+		"abc":    "abc",
+		"abcdef": "abcd... (truncated)",
+		"abcdéf": "abcd... (truncated)",
+		"abcéf":  "abc... (truncated)",
+	}
+	for value, expected := range testCases {
+		truncated := truncateLogValue(value, 4)
+		if truncated != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, value, truncated)
+		}
+		if !utf8.ValidString(truncated) {
+			t.Errorf("Truncated value %q must be valid UTF-8", truncated)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package kb
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/pkg/errors"
+)
+
+// defaultRetryStatusCodes is the HTTP status codes returned by Kibana on transient errors, like on rolling restart
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+}
+
+// nonIdempotentRetryStatusCodes is the HTTP status codes where Kibana don't process the request
+// Only them can retry non idempotent requests, like POST that create object. A 502 can be returned by proxy after the object is created
+var nonIdempotentRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+}
+
+// retryTransport is the HTTP transport that retry API calls on transient errors
+// It's used by all API calls, from go-kibana-rest and from custom API
+type retryTransport struct {
+	next        http.RoundTripper
+	backoff     backoffOptions
+	statusCodes []int
+}
+
+// newRetryTransport wrap the transport to retry API calls when Kibana answer with one of status codes
+func newRetryTransport(next http.RoundTripper, backoff backoffOptions, statusCodes []int) *retryTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &retryTransport{
+		next:        next,
+		backoff:     backoff,
+		statusCodes: statusCodes,
+	}
+}
+
+// RoundTrip run the request and retry it with exponential backoff, or with the delay asked by Retry-After header
+// The request body is rewinded before each retry. A request with body that can't be rewinded is not retried
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		res, err := t.next.RoundTrip(req)
+		if err != nil || attempt >= t.backoff.retry || !t.isRetryable(req, res.StatusCode) {
+			return res, err
+		}
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return res, nil
+		}
+
+		delay := retryAfterDelay(res.Header.Get("Retry-After"), time.Now())
+		if delay <= 0 {
+			delay = backoffDelay(attempt, t.backoff.wait, t.backoff.maxDelay)
+		} else if t.backoff.maxDelay > 0 && delay > t.backoff.maxDelay {
+			delay = t.backoff.maxDelay
+		}
+		tflog.Debug(req.Context(), "Retry API call on transient error", map[string]interface{}{
+			"status": res.StatusCode,
+			"method": req.Method,
+			"path":   req.URL.Path,
+			"delay":  delay.String(),
+		})
+
+		// Release the connexion before waiting
+		_, _ = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, errors.Wrapf(req.Context().Err(), "Stop to retry %s %s after %d attempts", req.Method, req.URL.Path, attempt+1)
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// isRetryable return true if the status code is one of retryable status codes
+// The non idempotent requests are only retried when Kibana don't process them.
+// The status API is not retried, because the connexion check already retry it
+func (t *retryTransport) isRetryable(req *http.Request, statusCode int) bool {
+	if req.URL.Path == basePathKibanaStatus || !containsInt(t.statusCodes, statusCode) {
+		return false
+	}
+
+	return isIdempotentMethod(req.Method) || containsInt(nonIdempotentRetryStatusCodes, statusCode)
+}
+
+// isIdempotentMethod return true if the request can be sent many times without side effect
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// containsInt return true if the value is on list
+func containsInt(list []int, value int) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// retryAfterDelay compute the delay from Retry-After header
+// The header can be a number of seconds or a HTTP date. It return 0 if the header is not set or not valid
+func retryAfterDelay(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return date.Sub(now)
+	}
+
+	return 0
+}
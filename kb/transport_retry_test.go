@@ -0,0 +1,187 @@
+package kb
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	kbapi "github.com/disaster37/go-kibana-rest/v8/kbapi"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// newTestScriptedHandler answer with the scripted status codes, then with 200 and body
+func newTestScriptedHandler(statusCodes []int, headers map[string]string, body string, calls *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/status" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name": "kibana", "version": {"number": "8.5.0"}}`))
+			return
+		}
+
+		payload, _ := io.ReadAll(r.Body)
+		*calls = append(*calls, string(payload))
+		if len(*calls) <= len(statusCodes) {
+			for key, value := range headers {
+				w.Header().Set(key, value)
+			}
+			w.WriteHeader(statusCodes[len(*calls)-1])
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	calls := []string{}
+	meta := newTestProviderMeta(t, newTestScriptedHandler(
+		[]int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusTooManyRequests},
+		nil,
+		`{"id": "test", "name": "test"}`,
+		&calls,
+	))
+	meta.client.Client.SetTransport(newRetryTransport(meta.client.Client.GetClient().Transport, backoffOptions{retry: 3}, defaultRetryStatusCodes))
+
+	// Retry go-kibana-rest call with body
+	space, err := meta.client.API.KibanaSpaces.Update(&kbapi.KibanaSpace{ID: "test", Name: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if space.ID != "test" {
+		t.Errorf("Unexpected space %+v", space)
+	}
+	if len(calls) != 4 {
+		t.Fatalf("Expected 4 calls, got %d", len(calls))
+	}
+	for _, payload := range calls {
+		if payload != calls[0] || payload == "" {
+			t.Errorf("The body must be sent on each retry, got %s", payload)
+		}
+	}
+
+	// Non idempotent call is not replayed when Kibana may have processed it
+	calls = []string{}
+	meta = newTestProviderMeta(t, newTestScriptedHandler([]int{http.StatusBadGateway}, nil, `{"id": "test", "name": "test"}`, &calls))
+	meta.client.Client.SetTransport(newRetryTransport(meta.client.Client.GetClient().Transport, backoffOptions{retry: 3}, defaultRetryStatusCodes))
+	if _, err = meta.client.API.KibanaSpaces.Create(&kbapi.KibanaSpace{ID: "test", Name: "test"}); err == nil {
+		t.Errorf("POST answered with 502 must not be retried")
+	}
+	if len(calls) != 1 {
+		t.Errorf("Expected 1 call, got %d", len(calls))
+	}
+
+	// Non idempotent call is retried when Kibana don't process it
+	calls = []string{}
+	meta = newTestProviderMeta(t, newTestScriptedHandler([]int{http.StatusTooManyRequests}, nil, `{"id": "test", "name": "test"}`, &calls))
+	meta.client.Client.SetTransport(newRetryTransport(meta.client.Client.GetClient().Transport, backoffOptions{retry: 3}, defaultRetryStatusCodes))
+	if _, err = meta.client.API.KibanaSpaces.Create(&kbapi.KibanaSpace{ID: "test", Name: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 {
+		t.Errorf("Expected 2 calls, got %d", len(calls))
+	}
+
+	// Not retryable status code
+	calls = []string{}
+	meta = newTestProviderMeta(t, newTestScriptedHandler([]int{http.StatusInternalServerError}, nil, `[]`, &calls))
+	meta.client.Client.SetTransport(newRetryTransport(meta.client.Client.GetClient().Transport, backoffOptions{retry: 3}, defaultRetryStatusCodes))
+	if _, err = getKibanaFeatures(context.Background(), meta.client.Client); err == nil {
+		t.Errorf("Error 500 must not be retried")
+	}
+	if len(calls) != 1 {
+		t.Errorf("Expected 1 call, got %d", len(calls))
+	}
+
+	// Too many failures
+	calls = []string{}
+	meta = newTestProviderMeta(t, newTestScriptedHandler([]int{503, 503, 503}, nil, `[]`, &calls))
+	meta.client.Client.SetTransport(newRetryTransport(meta.client.Client.GetClient().Transport, backoffOptions{retry: 1}, defaultRetryStatusCodes))
+	if _, err = getKibanaFeatures(context.Background(), meta.client.Client); err == nil {
+		t.Errorf("Error must be returned when retry is reached")
+	}
+	if len(calls) != 2 {
+		t.Errorf("Expected 2 calls, got %d", len(calls))
+	}
+
+	// Respect Retry-After header
+	calls = []string{}
+	meta = newTestProviderMeta(t, newTestScriptedHandler([]int{429}, map[string]string{"Retry-After": "1"}, `[]`, &calls))
+	meta.client.Client.SetTransport(newRetryTransport(meta.client.Client.GetClient().Transport, backoffOptions{retry: 1}, defaultRetryStatusCodes))
+	start := time.Now()
+	if _, err = getKibanaFeatures(context.Background(), meta.client.Client); err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) < time.Second {
+		t.Errorf("Retry-After must be respected, retried after %s", time.Since(start))
+	}
+
+	// Stop to wait when request is canceled
+	calls = []string{}
+	meta = newTestProviderMeta(t, newTestScriptedHandler([]int{503}, map[string]string{"Retry-After": "3600"}, `[]`, &calls))
+	meta.client.Client.SetTransport(newRetryTransport(meta.client.Client.GetClient().Transport, backoffOptions{retry: 1}, defaultRetryStatusCodes))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err = meta.client.Client.R().SetContext(ctx).Get(basePathKibanaFeature); err == nil {
+		t.Errorf("Canceled request must return error")
+	}
+}
+
+func TestProviderAPIRetry(t *testing.T) {
+	calls := []string{}
+	server := httptest.NewServer(newTestScriptedHandler([]int{http.StatusGatewayTimeout}, nil, `[]`, &calls))
+	t.Cleanup(server.Close)
+
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url":                    server.URL,
+		"retry":                  0,
+		"api_wait_before_retry":  0,
+		"api_retry_status_codes": []interface{}{504},
+	})
+	meta, diags := providerConfigure(context.Background(), d)
+	if diags.HasError() {
+		t.Fatalf("Unexpected error: %+v", diags)
+	}
+	if _, err := getKibanaFeatures(context.Background(), meta.(*providerMeta).client.Client); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 {
+		t.Errorf("Expected 2 calls, got %d", len(calls))
+	}
+}
+
+func TestRetryTransportSkipStatus(t *testing.T) {
+	calls := 0
+	meta := newTestProviderMeta(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	meta.client.Client.SetTransport(newRetryTransport(meta.client.Client.GetClient().Transport, backoffOptions{retry: 3}, defaultRetryStatusCodes))
+
+	// The connexion check already retry the status API
+	if _, err := getKibanaVersion(context.Background(), meta.client.Client); err == nil {
+		t.Errorf("Status API must failed")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestRetryAfterDelay(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := map[string]time.Duration{
+		"":                              0,
+		"5":                             5 * time.Second,
+		"-1":                            0,
+		"bad":                           0,
+		"Sun, 01 Jan 2023 00:00:10 GMT": 10 * time.Second,
+	}
+	for value, expected := range testCases {
+		if delay := retryAfterDelay(value, now); delay != expected {
+			t.Errorf("Expected %s for %q, got %s", expected, value, delay)
+		}
+	}
+}
@@ -2,7 +2,11 @@ package kb
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
 )
 
 // optionalInterfaceJSON permit to convert string as json object
@@ -24,6 +28,16 @@ func convertArrayInterfaceToArrayString(raws []interface{}) []string {
 	return data
 }
 
+// convertArrayInterfaceToArrayInt permit to convert an array of interface to an array of int
+func convertArrayInterfaceToArrayInt(raws []interface{}) []int {
+	data := make([]int, len(raws))
+	for i, raw := range raws {
+		data[i] = raw.(int)
+	}
+
+	return data
+}
+
 func convertInterfaceToJsonString(object interface{}) (string, error) {
 	if object == nil {
 		return "", nil
@@ -40,3 +54,57 @@ func convertInterfaceToJsonString(object interface{}) (string, error) {
 
 	return string(b), nil
 }
+
+// parseNDJSONObjects permit to get the id and type of each saved object on NDJSON string
+func parseNDJSONObjects(data string) ([]map[string]string, error) {
+	lines := splitNDJSON(data)
+	objects := make([]map[string]string, 0, len(lines))
+
+	for _, line := range lines {
+		object := map[string]any{}
+		if err := json.Unmarshal([]byte(line), &object); err != nil {
+			return nil, errors.Wrapf(err, "Error when unmarshal NDJSON line: %s", line)
+		}
+
+		// Export details line has no id and type
+		id, hasID := object["id"].(string)
+		objectType, hasType := object["type"].(string)
+		if !hasID || !hasType {
+			continue
+		}
+
+		objects = append(objects, map[string]string{
+			"id":   id,
+			"type": objectType,
+		})
+	}
+
+	return objects, nil
+}
+
+// parseImportObjects permit to convert string like type/id,type/id as list of objects
+func parseImportObjects(raw string) ([]map[string]string, error) {
+	objects := make([]map[string]string, 0, 1)
+
+	for _, item := range strings.Split(raw, ",") {
+		parts := strings.SplitN(item, "/", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("Object %s must be formated as <type>/<id>", item)
+		}
+		objects = append(objects, map[string]string{
+			"type": parts[0],
+			"id":   parts[1],
+		})
+	}
+
+	return objects, nil
+}
+
+// kibanaSpacePath permit to prefix API path with the user space
+func kibanaSpacePath(space string, path string) string {
+	if space == "" || space == "default" {
+		return path
+	}
+
+	return fmt.Sprintf("/s/%s%s", space, path)
+}
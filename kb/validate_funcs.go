@@ -0,0 +1,147 @@
+package kb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// clusterPrivileges is the list of Elasticsearch cluster privileges
+var clusterPrivileges = []string{
+	"all",
+	"cancel_task",
+	"create_snapshot",
+	"cross_cluster_replication",
+	"cross_cluster_search",
+	"delegate_pki",
+	"grant_api_key",
+	"manage",
+	"manage_api_key",
+	"manage_autoscaling",
+	"manage_behavioral_analytics",
+	"manage_ccr",
+	"manage_connector",
+	"manage_data_frame_transforms",
+	"manage_data_stream_global_retention",
+	"manage_enrich",
+	"manage_ilm",
+	"manage_index_templates",
+	"manage_inference",
+	"manage_ingest_pipelines",
+	"manage_logstash_pipelines",
+	"manage_ml",
+	"manage_oidc",
+	"manage_own_api_key",
+	"manage_pipeline",
+	"manage_rollup",
+	"manage_saml",
+	"manage_search_application",
+	"manage_search_query_rules",
+	"manage_search_synonyms",
+	"manage_security",
+	"manage_service_account",
+	"manage_slm",
+	"manage_token",
+	"manage_transform",
+	"manage_user_profile",
+	"manage_watcher",
+	"monitor",
+	"monitor_connector",
+	"monitor_data_frame_transforms",
+	"monitor_data_stream_global_retention",
+	"monitor_enrich",
+	"monitor_inference",
+	"monitor_ml",
+	"monitor_rollup",
+	"monitor_snapshot",
+	"monitor_stats",
+	"monitor_text_structure",
+	"monitor_transform",
+	"monitor_watcher",
+	"none",
+	"post_behavioral_analytics_event",
+	"read_ccr",
+	"read_connector_secrets",
+	"read_fleet_secrets",
+	"read_ilm",
+	"read_pipeline",
+	"read_security",
+	"read_slm",
+	"transport_client",
+	"write_connector_secrets",
+	"write_fleet_secrets",
+}
+
+// indexPrivileges is the list of Elasticsearch index privileges
+var indexPrivileges = []string{
+	"all",
+	"auto_configure",
+	"create",
+	"create_doc",
+	"create_index",
+	"cross_cluster_replication",
+	"cross_cluster_replication_internal",
+	"delete",
+	"delete_index",
+	"index",
+	"maintenance",
+	"manage",
+	"manage_data_stream_lifecycle",
+	"manage_failure_store",
+	"manage_follow_index",
+	"manage_ilm",
+	"manage_leader_index",
+	"monitor",
+	"none",
+	"read",
+	"read_cross_cluster",
+	"read_failure_store",
+	"view_index_metadata",
+	"write",
+}
+
+// remoteClusterPrivileges is the list of Elasticsearch remote cluster privileges
+var remoteClusterPrivileges = []string{
+	"monitor_enrich",
+	"monitor_stats",
+}
+
+// validatePrivilege permit to check the privilege is a known privilege name or an action name like indices:data/read/*
+func validatePrivilege(privileges []string) schema.SchemaValidateDiagFunc {
+	return func(i interface{}, path cty.Path) diag.Diagnostics {
+		privilege, ok := i.(string)
+		if !ok {
+			return diag.Diagnostics{
+				{
+					Severity:      diag.Error,
+					Summary:       "Privilege must be a string",
+					Detail:        fmt.Sprintf("Expected type of privilege to be string, got %T", i),
+					AttributePath: path,
+				},
+			}
+		}
+
+		// Action names are not checked
+		if strings.Contains(privilege, ":") {
+			return nil
+		}
+
+		for _, validPrivilege := range privileges {
+			if privilege == validPrivilege {
+				return nil
+			}
+		}
+
+		return diag.Diagnostics{
+			{
+				Severity:      diag.Error,
+				Summary:       fmt.Sprintf("Unknown privilege %q", privilege),
+				Detail:        fmt.Sprintf("Expected one of [%s], or an action name like indices:data/read/*", strings.Join(privileges, ", ")),
+				AttributePath: path,
+			},
+		}
+	}
+}
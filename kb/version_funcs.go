@@ -0,0 +1,75 @@
+package kb
+
+import (
+	"context"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// versionRequirement is the minimal Kibana version needed to use an attribute
+type versionRequirement struct {
+	attribute string
+	version   string
+	isUsed    func(d *schema.ResourceDiff) bool
+}
+
+// customizeDiffMinimalVersion permit to fail at plan time when an attribute is used with Kibana version that not support it
+// With lazy connect, the Kibana version is resolved only when an attribute that need it is used.
+// The check is skipped when the Kibana version is unknown
+func customizeDiffMinimalVersion(requirements ...versionRequirement) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+		m, ok := meta.(*providerMeta)
+		if !ok {
+			return nil
+		}
+
+		for _, requirement := range requirements {
+			if !requirement.isUsed(d) {
+				continue
+			}
+			if m.lazyConnect {
+				if err := m.connect(ctx); err != nil {
+					tflog.Warn(ctx, "Kibana version is unknown, the minimal version is not checked", map[string]interface{}{"attribute": requirement.attribute, "version": requirement.version, "error": err.Error()})
+					return nil
+				}
+			}
+			if m.version != nil && isVersionLessThan(m.version, requirement.version) {
+				return errors.Errorf("%s need Kibana %s or newer, but the Kibana version is %s", requirement.attribute, requirement.version, m.version)
+			}
+		}
+
+		return nil
+	}
+}
+
+// isAttributeSet return true if the attribute is not empty
+func isAttributeSet(key string) func(d *schema.ResourceDiff) bool {
+	return func(d *schema.ResourceDiff) bool {
+		switch v := d.Get(key).(type) {
+		case string:
+			return v != ""
+		case bool:
+			return v
+		case []interface{}:
+			return len(v) > 0
+		case *schema.Set:
+			return v.Len() > 0
+		case map[string]interface{}:
+			return len(v) > 0
+		default:
+			return false
+		}
+	}
+}
+
+// isVersionLessThan compare Kibana version with minimal version without take care of pre release, like 8.10.0-SNAPSHOT
+func isVersionLessThan(current *semver.Version, minimal string) bool {
+	version := *current
+	version.PreRelease = ""
+	version.Metadata = ""
+
+	return version.LessThan(*semver.New(minimal))
+}
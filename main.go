@@ -2,21 +2,17 @@ package main
 
 import (
 	"flag"
-	"os"
+	"io"
 
 	"github.com/disaster37/terraform-provider-kibana/v8/kb"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
 	log "github.com/sirupsen/logrus"
-	easy "github.com/t-tomalak/logrus-easy-formatter"
 )
 
 func init() {
 
-	log.SetOutput(os.Stderr)
-	log.SetLevel(log.DebugLevel)
-	log.SetFormatter(&easy.Formatter{
-		LogFormat: "[%lvl%] %msg%\n",
-	})
+	// The provider logs with tflog, so the logs of Kibana client library are discarded
+	log.SetOutput(io.Discard)
 
 }
 